@@ -104,7 +104,7 @@ sleep 0.2
 # ─────────────────────────────────────────────────────────────────────────────
 # Step 1: System Dependencies — Check
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 1 6 "Checking system dependencies"
+step_header 1 7 "Checking system dependencies"
 
 MISSING_DEPS=()
 
@@ -173,7 +173,7 @@ sleep 0.1
 # ─────────────────────────────────────────────────────────────────────────────
 # Step 2: System Dependencies — Install
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 2 6 "Installing missing dependencies"
+step_header 2 7 "Installing missing dependencies"
 
 if [ ${#MISSING_DEPS[@]} -eq 0 ]; then
     ok "Nothing to install — all dependencies present"
@@ -243,7 +243,7 @@ fi
 # ─────────────────────────────────────────────────────────────────────────────
 # Step 3: AI Model
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 3 6 "Verifying AI model"
+step_header 3 7 "Verifying AI model"
 
 MODEL_FILE="$SCRIPT_DIR/smollm2-135m.gguf"
 MODEL_URL="https://github.com/brookcs3/aiGn-cli/releases/download/v1.0/smollm2-135m.gguf"
@@ -287,7 +287,7 @@ fi
 # ─────────────────────────────────────────────────────────────────────────────
 # Step 4: Permissions
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 4 6 "Setting executable permissions"
+step_header 4 7 "Setting executable permissions"
 
 chmod +x "$SCRIPT_DIR/career_agent.sh"
 ok "career_agent.sh"
@@ -310,7 +310,7 @@ sleep 0.2
 # ─────────────────────────────────────────────────────────────────────────────
 # Step 5: Python Environment
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 5 6 "Setting up Python environment"
+step_header 5 7 "Setting up Python environment"
 
 VENV_DIR="$SCRIPT_DIR/.venv"
 
@@ -331,9 +331,27 @@ pip install -r "$SCRIPT_DIR/requirements.txt" -q 2>&1 | tail -1
 ok "Python environment ready"
 
 # ─────────────────────────────────────────────────────────────────────────────
-# Step 6: Launcher
+# Step 6: Cover Letter Editor
 # ─────────────────────────────────────────────────────────────────────────────
-step_header 6 6 "Creating launcher"
+step_header 6 7 "Building the cover letter editor"
+
+EDITOR_BIN="$SCRIPT_DIR/src/GumMouse/cover-letter-editor"
+if command -v go &> /dev/null; then
+    echo -e "  ${CYAN}→${NC} Building src/GumMouse..."
+    if (cd "$SCRIPT_DIR/src/GumMouse" && go build -o "$EDITOR_BIN" .); then
+        ok "src/GumMouse/cover-letter-editor"
+    else
+        warn "Build failed — 'aign recent' won't be available"
+    fi
+else
+    warn "Go not found — skipping; 'aign recent' needs the editor built"
+fi
+sleep 0.2
+
+# ─────────────────────────────────────────────────────────────────────────────
+# Step 7: Launcher
+# ─────────────────────────────────────────────────────────────────────────────
+step_header 7 7 "Creating launcher"
 
 LAUNCHER="$SCRIPT_DIR/aign"
 cat > "$LAUNCHER" << 'LAUNCHER_EOF'
@@ -345,6 +363,17 @@ SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
 # Activate virtual environment
 source "$SCRIPT_DIR/.venv/bin/activate"
 
+# "aign recent" reopens a letter from the editor's history
+if [ "$1" = "recent" ]; then
+    shift
+    EDITOR_BIN="$SCRIPT_DIR/src/GumMouse/cover-letter-editor"
+    if [ ! -x "$EDITOR_BIN" ]; then
+        echo "The cover letter editor isn't built: install Go and re-run ./install.sh" >&2
+        exit 1
+    fi
+    exec "$EDITOR_BIN" -recent "$@"
+fi
+
 # Run the career agent
 exec "$SCRIPT_DIR/career_agent.sh" "$@"
 LAUNCHER_EOF
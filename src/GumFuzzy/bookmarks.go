@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"aign/render/config"
+	"aign/render/plain"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bookmarkIcon is put before the title of a bookmarked folder.
+var bookmarkIcon = "⭐ "
+
+// bookmarksPath is the bookmarks file next to the config file:
+// $XDG_CONFIG_HOME/aign/bookmarks.txt, one folder per line.
+func bookmarksPath() (string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "bookmarks.txt"), nil
+}
+
+// loadBookmarks reads the bookmarked folders. A missing file has none.
+func loadBookmarks() ([]string, error) {
+	path, err := bookmarksPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if dir := strings.TrimSpace(scanner.Text()); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, scanner.Err()
+}
+
+// saveBookmarks replaces the bookmarks file with dirs.
+func saveBookmarks(dirs []string) error {
+	path, err := bookmarksPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, dir := range dirs {
+		sb.WriteString(dir + "\n")
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
+
+// pinBookmarks flags the bookmarked folders in items and moves them to
+// the front, after any ".." entry, keeping their order.
+func (o options) pinBookmarks(items []list.Item) {
+	start := 0
+	if len(items) > 0 && items[0].(item).title == ".." {
+		start = 1
+	}
+	for idx, li := range items[start:] {
+		if i := li.(item); i.isDir && slices.Contains(o.bookmarks, i.path) {
+			i.bookmarked = true
+			items[start+idx] = i
+		}
+	}
+	rest := items[start:]
+	slices.SortStableFunc(rest, func(a, b list.Item) int {
+		switch pa, pb := a.(item).bookmarked, b.(item).bookmarked; {
+		case pa && !pb:
+			return -1
+		case pb && !pa:
+			return 1
+		}
+		return 0
+	})
+}
+
+// toggleBookmark bookmarks the highlighted folder, or removes its
+// bookmark, and saves the change.
+func (m *model) toggleBookmark() {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || !i.isDir || i.title == ".." {
+		m.err = "Only folders can be bookmarked"
+		return
+	}
+	dirs := slices.Clone(m.opts.bookmarks)
+	if n := slices.Index(dirs, i.path); n != -1 {
+		dirs = slices.Delete(dirs, n, n+1)
+		m.notice = "Removed bookmark " + filepath.Base(i.path)
+	} else {
+		dirs = append(dirs, i.path)
+		m.notice = "Bookmarked " + filepath.Base(i.path)
+	}
+	if err := saveBookmarks(dirs); err != nil {
+		slog.Error("save bookmarks", "err", err)
+		m.notice = ""
+		m.err = "Could not save bookmarks: " + err.Error()
+		return
+	}
+	m.opts.bookmarks = dirs
+
+	items := m.list.Items()
+	for idx, li := range items {
+		if it, ok := li.(item); ok {
+			it.bookmarked = false
+			items[idx] = it
+		}
+	}
+	m.opts.pinBookmarks(items)
+	m.list.SetItems(items)
+	for idx, li := range items {
+		if li.(item).path == i.path {
+			m.list.Select(idx)
+			break
+		}
+	}
+}
+
+// bookmark is an entry in the bookmarks menu.
+type bookmark string
+
+func (b bookmark) FilterValue() string { return string(b) }
+func (b bookmark) Title() string       { return filepath.Base(string(b)) }
+func (b bookmark) Description() string { return string(b) }
+
+// bookmarkMenu lists the bookmarked folders to jump to.
+type bookmarkMenu struct {
+	list list.Model
+}
+
+// openBookmarks shows the bookmarks menu, or says how to add one.
+func (m *model) openBookmarks() {
+	if len(m.opts.bookmarks) == 0 {
+		m.notice = "No bookmarks yet: press " + m.keys.Bookmark.Help().Key + " on a folder"
+		return
+	}
+	items := make([]list.Item, len(m.opts.bookmarks))
+	for i, dir := range m.opts.bookmarks {
+		items[i] = bookmark(dir)
+	}
+
+	h, v := docStyle.GetFrameSize()
+	l := list.New(items, list.NewDefaultDelegate(), m.width-h, m.height-v-footerHeight)
+	if plain.Enabled() {
+		plainList(&l)
+	}
+	l.Title = "Bookmarks"
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("bookmark", "bookmarks")
+	l.Styles.Title = titleStyle
+	m.bookmarks = &bookmarkMenu{list: l}
+}
+
+// updateBookmarks handles keys while the bookmarks menu is open. Enter
+// goes to the chosen folder.
+func (m *model) updateBookmarks(msg tea.KeyMsg) tea.Cmd {
+	filtering := m.bookmarks.list.FilterState() == list.Filtering
+	switch {
+	case msg.String() == "ctrl+c":
+		m.quitting = true
+		return tea.Quit
+	case msg.String() == "esc" && m.bookmarks.list.FilterState() == list.Unfiltered,
+		msg.String() == "q" && !filtering:
+		m.bookmarks = nil
+		return nil
+	case msg.String() == "enter" && !filtering:
+		b, ok := m.bookmarks.list.SelectedItem().(bookmark)
+		m.bookmarks = nil
+		if ok && m.changeDir(string(b)) {
+			m.list.ResetFilter()
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	m.bookmarks.list, cmd = m.bookmarks.list.Update(msg)
+	return cmd
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"aign/render/plain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboard is a file marked with c or m, waiting to be pasted into
+// another directory with p.
+type clipboard struct {
+	path string
+	move bool
+}
+
+func (c clipboard) verb() string {
+	if c.move {
+		return "move"
+	}
+	return "copy"
+}
+
+// markFile puts the highlighted file on the clipboard.
+func (m *model) markFile(move bool) {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || i.title == ".." {
+		return
+	}
+	if i.isDir {
+		m.err = "Only files can be copied or moved"
+		return
+	}
+	m.clip = &clipboard{path: i.path, move: move}
+	slog.Debug("marked", "path", i.path, "op", m.clip.verb())
+}
+
+// paste copies or moves the clipboard file into the current directory. If
+// a file of that name is already there, it asks first.
+func (m *model) paste() {
+	if m.clip == nil {
+		m.err = "Nothing to paste: mark a file with " + m.keys.Copy.Help().Key + " or " + m.keys.Move.Help().Key
+		return
+	}
+	dst := filepath.Join(m.currentDir, filepath.Base(m.clip.path))
+	if dst == m.clip.path && m.clip.move {
+		m.clip = nil
+		return
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		m.collision = dst
+		return
+	}
+	m.finishPaste(dst)
+}
+
+// updateCollision answers the prompt shown when the pasted file's name is
+// taken: overwrite it, paste under a free name, or give up.
+func (m *model) updateCollision(msg tea.KeyMsg) tea.Cmd {
+	dst := m.collision
+	switch msg.String() {
+	case "o":
+		if dst == m.clip.path {
+			m.err = "Can't overwrite a file with itself"
+			break
+		}
+		m.finishPaste(dst)
+	case "r":
+		m.finishPaste(freeName(dst))
+	case "esc", "n":
+		m.notice = "Paste cancelled"
+	case "ctrl+c":
+		m.quitting = true
+		return tea.Quit
+	default:
+		return nil
+	}
+	m.collision = ""
+	return nil
+}
+
+func (m *model) finishPaste(dst string) {
+	op := m.clip.verb()
+	_, statErr := os.Lstat(dst)
+	replaced := statErr == nil
+	var err error
+	if m.clip.move {
+		err = moveFile(m.clip.path, dst)
+	} else {
+		err = copyFile(m.clip.path, dst)
+	}
+	if err != nil {
+		slog.Warn("paste failed", "op", op, "src", m.clip.path, "dst", dst, "err", err)
+		m.err = fmt.Sprintf("Cannot %s %s: %v", op, filepath.Base(m.clip.path), err)
+		return
+	}
+	slog.Info("pasted", "op", op, "src", m.clip.path, "dst", dst)
+	info, _ := os.Lstat(dst)
+	m.logOp(fileOp{move: m.clip.move, src: m.clip.path, dst: dst, replaced: replaced, info: info})
+
+	if m.clip.move {
+		m.clip = nil
+	}
+	m.list.ResetFilter()
+	m.changeDir(m.currentDir)
+	for idx, li := range m.list.Items() {
+		if li.(item).path == dst {
+			m.list.Select(idx)
+			break
+		}
+	}
+	if op == "move" {
+		m.notice = "Moved to " + dst
+	} else {
+		m.notice = "Copied to " + dst
+	}
+}
+
+// freeName returns path, or the first of "name (1).ext", "name (2).ext"…
+// that doesn't exist yet.
+func freeName(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+// copyFile copies src's contents and permissions to dst, replacing it.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// moveFile renames src to dst, copying and removing it when they are on
+// different filesystems.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// clipboardStatus is the footer line while a file is marked.
+func (m model) clipboardStatus() string {
+	if m.collision != "" {
+		return fmt.Sprintf("%s already exists: o = overwrite • r = keep both • esc = cancel", filepath.Base(m.collision))
+	}
+	if m.clip == nil {
+		return ""
+	}
+	return fmt.Sprintf(plain.Icon("📋 ", "")+"%s %s • %s = paste here", m.clip.verb(), m.clip.path, m.keys.Paste.Help().Key)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// contentWorkers bounds how many files are read at once.
+	contentWorkers = 8
+	// contentMaxBytes caps how much of each file is scanned.
+	contentMaxBytes = 1 << 20
+	// snippetWidth is how much of a matching line is shown.
+	snippetWidth = 80
+)
+
+// contentResultsMsg carries the files whose contents matched query.
+type contentResultsMsg struct {
+	query string
+	items []list.Item
+}
+
+// startContentSearch opens the query line. The current listing is kept so
+// every query searches the directory rather than the previous results.
+func (m *model) startContentSearch() tea.Cmd {
+	m.contentMode = true
+	m.contentItems = m.list.Items()
+	m.contentInput.SetValue("")
+	m.resize()
+	return m.contentInput.Focus()
+}
+
+// updateContentSearch handles keys while content search is active. It
+// reports false for keys the list should handle instead, which is every key
+// once results are showing and the query line is no longer focused.
+func (m *model) updateContentSearch(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		if m.list.FilterState() != list.Unfiltered {
+			return nil, false
+		}
+		m.contentMode = false
+		m.contentItems = nil
+		m.contentInput.Blur()
+		m.list.Title = m.title()
+		m.resize()
+		m.changeDir(m.currentDir)
+		return nil, true
+	}
+	if key.Matches(msg, m.keys.ContentSearch) {
+		if m.list.FilterState() == list.Filtering {
+			return nil, false
+		}
+		return m.contentInput.Focus(), true
+	}
+
+	if !m.contentInput.Focused() {
+		return nil, false
+	}
+
+	if msg.String() == "enter" {
+		query := m.contentInput.Value()
+		if query == "" {
+			return nil, true
+		}
+		m.contentInput.Blur()
+		slog.Debug("content search", "dir", m.currentDir, "query", query)
+		m.list.Title = fmt.Sprintf("Searching for %q…", query)
+		return searchContents(m.contentItems, query), true
+	}
+
+	var cmd tea.Cmd
+	m.contentInput, cmd = m.contentInput.Update(msg)
+	return cmd, true
+}
+
+func (m *model) showContentResults(msg contentResultsMsg) {
+	if !m.contentMode {
+		return
+	}
+	slog.Debug("content search done", "query", msg.query, "matches", len(msg.items))
+	m.list.Title = fmt.Sprintf("%d files containing %q", len(msg.items), msg.query)
+	m.list.ResetFilter()
+	m.list.SetItems(msg.items)
+	m.list.ResetSelected()
+}
+
+// searchContents scans the files among items for query, case-insensitively,
+// with a bounded pool of readers. Matches keep their listing order and get
+// the first matching line as their description. A panic in a reader is
+// raised again from the command itself, where bubbletea can catch it and
+// restore the terminal.
+func searchContents(items []list.Item, query string) tea.Cmd {
+	return func() tea.Msg {
+		needle := strings.ToLower(query)
+		results := make([]list.Item, len(items))
+		sem := make(chan struct{}, contentWorkers)
+		var wg sync.WaitGroup
+		var panicOnce sync.Once
+		var panicked any
+
+		for idx, li := range items {
+			i, ok := li.(item)
+			if !ok || i.isDir {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				defer func() {
+					if r := recover(); r != nil {
+						panicOnce.Do(func() { panicked = r })
+					}
+				}()
+				if line, n, ok := grepFile(i.path, needle); ok {
+					i.desc = fmt.Sprintf("L%d: %s", n, line)
+					results[idx] = i
+				}
+			}()
+		}
+		wg.Wait()
+		if panicked != nil {
+			panic(panicked)
+		}
+
+		var matches []list.Item
+		for _, r := range results {
+			if r != nil {
+				matches = append(matches, r)
+			}
+		}
+		return contentResultsMsg{query: query, items: matches}
+	}
+}
+
+// grepFile returns the first line of path containing needle (already
+// lower-cased) and its line number. Binary files never match.
+func grepFile(path, needle string) (string, int, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, contentMaxBytes))
+	if err != nil || isBinary(data) {
+		return "", 0, false
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), contentMaxBytes)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.Contains(strings.ToLower(line), needle) {
+			line = strings.TrimSpace(line)
+			if r := []rune(line); len(r) > snippetWidth {
+				line = string(r[:snippetWidth-1]) + "…"
+			}
+			return line, n, true
+		}
+	}
+	return "", 0, false
+}
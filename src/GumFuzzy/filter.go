@@ -0,0 +1,63 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterDebounce is how long typing must pause before the list is
+// re-filtered, so large directories don't re-rank on every keystroke.
+const filterDebounce = 50 * time.Millisecond
+
+// filterTickMsg fires after filterDebounce; it carries the filter sequence
+// number it was scheduled for so stale ticks can be ignored.
+type filterTickMsg int
+
+func debounceFilter(seq int) tea.Cmd {
+	return tea.Tick(filterDebounce, func(time.Time) tea.Msg {
+		return filterTickMsg(seq)
+	})
+}
+
+// minLengthFilter wraps filter so queries shorter than n runes match every
+// target in its original order.
+func minLengthFilter(n int, filter list.FilterFunc) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		if len([]rune(term)) >= n {
+			return filter(term, targets)
+		}
+		ranks := make([]list.Rank, len(targets))
+		for i := range targets {
+			ranks[i] = list.Rank{Index: i}
+		}
+		return ranks
+	}
+}
+
+// startFiltering opens the filter input with query already typed and
+// applied, as if the user had pressed / and typed it.
+func (m *model) startFiltering(query string) {
+	m.list.SetFilterText(query)
+	m.list.SetFilterState(list.Filtering)
+}
+
+// filterFlag is -filter: on its own it starts the picker filtering, and
+// -filter=query also types query in. Being a boolean-style flag, a query
+// given as "-filter query" arrives as the first argument instead.
+type filterFlag struct {
+	set   bool
+	query string
+}
+
+func (f *filterFlag) String() string   { return f.query }
+func (f *filterFlag) IsBoolFlag() bool { return true }
+
+func (f *filterFlag) Set(s string) error {
+	f.set = true
+	if s != "true" {
+		f.query = s
+	}
+	return nil
+}
@@ -3,12 +3,26 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"aign/render/config"
+	"aign/render/logging"
+	"aign/render/palette"
+	"aign/render/plain"
+	"aign/render/terminal"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 )
 
 var (
@@ -20,33 +34,134 @@ var (
 			MarginBottom(1)
 
 	docStyle = lipgloss.NewStyle().Margin(1, 2)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F87")).
+			Bold(true)
+
+	noticeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#73F59F"))
 )
 
+// footerHeight is the row reserved below the list for error and status
+// messages.
+const footerHeight = 1
+
 type item struct {
 	title, desc string
 	path        string
 	isDir       bool
+	git         string // status code under -git, empty if unchanged
+	marked      bool   // chosen with space
+	bookmarked  bool   // in bookmarks.txt, so pinned to the top
+	line        bool   // read from stdin under -stdin, not necessarily a path
+	size        int64
+}
+
+// Title is the entry's name, after a bookmark star and a mark when it is
+// chosen, and followed by its git status under -git.
+func (i item) Title() string {
+	title := i.title
+	if i.bookmarked {
+		title = bookmarkIcon + title
+	}
+	if i.marked {
+		title = markIcon + title
+	}
+	if i.git == "" {
+		return title
+	}
+	return title + " " + gitStatusStyles[i.git].Render(i.git)
 }
 
-func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.title }
 
+// options holds the command-line settings that shape directory listings,
+// along with the access log that -recent sorts by.
+type options struct {
+	recursive      bool
+	followSymlinks bool
+	verbose        bool
+	printDir       bool
+	dirsOnly       bool
+	recent         bool
+	lastOpened     map[string]int64
+	minFilterLen   int
+	treeOut        string
+	git            bool
+	print0         bool
+	lines          []string // entries read from stdin under -stdin
+	maxDepth       int      // directory levels -recursive descends; negative is unlimited
+	minSize        int64    // smallest file listed, in bytes
+	maxSize        int64    // largest file listed, in bytes; 0 is no limit
+	exts           []string // extensions of the files listed, lowercase without the dot
+	glob           string   // pattern the names of the files listed match
+	bookmarks      []string // folders pinned to the top, from bookmarks.txt
+}
+
+// defaultMaxDepth is how deep -recursive goes without -max-depth.
+const defaultMaxDepth = 5
+
 type model struct {
 	list         list.Model
+	opts         options
 	currentDir   string
 	selectedFile string
+	chosen       []string        // the marked files, when enter took them
+	selected     map[string]bool // files marked with space, kept across folders
+	anchor       string          // last file marked or unmarked, where a range starts
+	printBoth    bool
 	quitting     bool
 	height       int
 	width        int
+	err          string
+	notice       string
+	tree         string
+	cycles       []string
+	filterSeq    int
+	contentMode  bool
+	contentInput textinput.Model
+	contentItems []list.Item
+	keys         keyMap
+	walk         *walkState
+	preview      viewport.Model
+	previewPath  string
+	previewWidth int // the preview's width when previewPath was loaded
+	split        float64
+	clip         *clipboard
+	collision    string
+	ops          []fileOp // pastes u can undo, oldest first
+	palette      *palette.Palette
+	bookmarks    *bookmarkMenu
+	showHidden   bool // list dotfiles, toggled with ctrl+h
 }
 
-func getItems(dir string) []list.Item {
+func (m *model) getItems(dir string) ([]list.Item, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
+	var found []list.Item
+	if m.opts.recursive {
+		found = m.walkItems(dir)
+	} else {
+		for _, entry := range entries {
+			i := newItem(filepath.Join(dir, entry.Name()), entry.Name(), entry)
+			if i.isDir {
+				i.desc += " | " + entryCount(i.path)
+			}
+			found = append(found, i)
+		}
+	}
+	return m.listing(dir, found), nil
+}
+
+// listing puts the parent entry before the entries found in dir and
+// applies -dirs-only, the size range, -recent and the bookmarks. Dotfiles
+// are left out unless they are being shown.
+func (m *model) listing(dir string, found []list.Item) []list.Item {
 	var items []list.Item
 	if dir != "/" {
 		items = append(items, item{
@@ -56,92 +171,623 @@ func getItems(dir string) []list.Item {
 			isDir: true,
 		})
 	}
+	items = append(items, m.hiddenFiltered(dir, found)...)
 
-	for _, entry := range entries {
-		info, _ := entry.Info()
-		prefix := "📄 "
-		if entry.IsDir() {
-			prefix = "📁 "
+	if m.opts.dirsOnly {
+		dirs := items[:0]
+		for _, li := range items {
+			if li.(item).isDir {
+				dirs = append(dirs, li)
+			}
 		}
-		items = append(items, item{
-			title: prefix + entry.Name(),
-			desc:  fmt.Sprintf("%s | %d bytes", info.ModTime().Format("2006-01-02"), info.Size()),
-			path:  filepath.Join(dir, entry.Name()),
-			isDir: entry.IsDir(),
-		})
+		items = dirs
+	}
+	items = m.opts.sizeFiltered(items)
+	items = m.opts.nameFiltered(items)
+
+	if m.opts.recent {
+		sortRecent(items, m.opts.lastOpened)
 	}
+	m.opts.pinBookmarks(items)
+	if m.opts.git {
+		annotateGit(items, dir)
+	}
+	m.markListing(items)
 	return items
 }
 
+// newItem builds the list entry for path, titled with name and described
+// by its date and, for a file, its size. A folder's entry count costs a
+// read of the folder, so only a plain listing adds it; a recursive walk
+// would read every folder twice.
+func newItem(path, name string, entry fs.DirEntry) item {
+	info, _ := entry.Info()
+	prefix := plain.Icon("📄 ", "")
+	desc := info.ModTime().Format("2006-01-02")
+	if entry.IsDir() {
+		prefix = plain.Icon("📁 ", "")
+	} else {
+		desc += " | " + humanSize(info.Size())
+	}
+	return item{
+		title: prefix + name,
+		desc:  desc,
+		path:  path,
+		isDir: entry.IsDir(),
+		size:  info.Size(),
+	}
+}
+
+// entryCount says how many entries the folder at path holds, or "—" if
+// it can't be read.
+func entryCount(path string) string {
+	entries, err := os.ReadDir(path)
+	switch {
+	case err != nil:
+		return "—"
+	case len(entries) == 1:
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", len(entries))
+}
+
+// changeDir lists dir and makes it the current directory. If dir can't be
+// read, the current listing is kept and the reason is shown in the footer.
+// In recursive mode only dir itself is read here; the entries below it
+// stream in from a background walk.
+func (m *model) changeDir(dir string) bool {
+	var items []list.Item
+	var err error
+	if m.opts.recursive {
+		_, err = os.ReadDir(dir)
+	} else {
+		items, err = m.getItems(dir)
+	}
+	if err != nil {
+		slog.Warn("change dir failed", "dir", dir, "err", err)
+		m.err = dirError(dir, err)
+		return false
+	}
+	slog.Debug("change dir", "dir", dir, "entries", len(items))
+	m.err = ""
+	m.currentDir = dir
+	if m.opts.recursive {
+		m.startWalk(dir)
+		items = m.listing(dir, nil)
+	}
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+	return true
+}
+
+func dirError(dir string, err error) string {
+	if os.IsPermission(err) {
+		return "Permission denied: " + dir
+	}
+	return fmt.Sprintf("Cannot open %s: %v", dir, err)
+}
+
+// title names what is being picked.
+func (m model) title() string {
+	if m.opts.lines != nil {
+		return "CAREER AI: SELECT"
+	}
+	title := "CAREER AI: SELECT FILE"
+	if m.opts.dirsOnly {
+		title = "CAREER AI: SELECT FOLDER (. to choose)"
+	}
+	if m.opts.recursive {
+		depth := "unlimited"
+		if m.opts.maxDepth >= 0 {
+			depth = fmt.Sprint(m.opts.maxDepth)
+		}
+		title += " • depth " + depth
+	}
+	if r := m.opts.sizeRange(); r != "" {
+		title += " • " + r
+	}
+	if f := m.opts.nameFilter(); f != "" {
+		title += " • " + f
+	}
+	if m.showHidden {
+		title += " [hidden shown]"
+	}
+	return title
+}
+
+func newModel(startDir string, opts options) model {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	if plain.Enabled() {
+		plainList(&l)
+	}
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.Filter = minLengthFilter(opts.minFilterLen, list.DefaultFilter)
+
+	ci := textinput.New()
+	ci.Prompt = plain.Icon("🔎 ", "") + "Search contents: "
+	ci.Placeholder = "phrase inside a file"
+
+	m := model{
+		list:         l,
+		opts:         opts,
+		currentDir:   startDir,
+		contentInput: ci,
+		preview:      viewport.New(0, 0),
+		split:        defaultSplit,
+		selected:     make(map[string]bool),
+	}
+	m.list.Title = m.title()
+	m.setKeys(defaultKeyMap())
+	if opts.lines != nil {
+		m.list.SetItems(lineItems(opts.lines))
+	} else {
+		m.changeDir(startDir)
+	}
+	m.syncPreview()
+	return m
+}
+
+// setKeys installs km, listing the picker's own keys in the list's full
+// help.
+func (m *model) setKeys(km keyMap) {
+	m.keys = km
+	m.list.AdditionalFullHelpKeys = km.help
+	// The list quits by itself as well; keep it on the same keys, plus
+	// its usual esc.
+	m.list.KeyMap.Quit.SetKeys(append(km.Quit.Keys(), "esc")...)
+	m.list.KeyMap.Quit.SetHelp(km.Quit.Help().Key, km.Quit.Help().Desc)
+}
+
 func (m model) Init() tea.Cmd {
+	if m.walk != nil {
+		return waitForWalk(m.walk)
+	}
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	walk := m.walk
+	m, cmd := m.update(msg)
+	m.syncPreview()
+	// A walk started by this update needs someone listening to it.
+	if m.walk != nil && m.walk != walk {
+		cmd = tea.Batch(cmd, waitForWalk(m.walk))
+	}
+	return m, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
+	if m.collision == "" && m.palette == nil && m.bookmarks == nil && m.scrollPreview(msg) {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" || msg.String() == "q" {
+		m.err = ""
+		m.notice = ""
+
+		if m.collision != "" {
+			return m, m.updateCollision(msg)
+		}
+
+		if m.palette != nil {
+			b, cmd := m.updatePalette(msg)
+			if b == nil {
+				return m, cmd
+			}
+			return m.runAction(*b)
+		}
+
+		if m.bookmarks != nil {
+			return m, m.updateBookmarks(msg)
+		}
+
+		if msg.String() == "ctrl+c" {
+			slog.Debug("quit", "key", msg.String())
 			m.quitting = true
 			return m, tea.Quit
 		}
 
-		if msg.String() == "enter" {
+		if msg.String() == "esc" && m.walk != nil && m.list.FilterState() != list.Filtering {
+			m.walk.cancel()
+			m.notice = "Cancelling scan…"
+			return m, nil
+		}
+
+		// Lines from stdin aren't a directory to search, export or paste
+		// into.
+		if m.opts.lines != nil && m.list.FilterState() != list.Filtering &&
+			key.Matches(msg, m.keys.ContentSearch, m.keys.ExportTree, m.keys.Copy, m.keys.Move, m.keys.Paste, m.keys.Undo, m.keys.Bookmark, m.keys.Bookmarks, m.keys.ToggleHidden) {
+			m.err = "Not available when picking from stdin"
+			return m, nil
+		}
+
+		if m.contentMode {
+			if cmd, handled := m.updateContentSearch(msg); handled {
+				return m, cmd
+			}
+		} else if key.Matches(msg, m.keys.ContentSearch) && m.list.FilterState() != list.Filtering {
+			return m, m.startContentSearch()
+		}
+
+		if key.Matches(msg, m.keys.Palette) && !m.contentMode && m.list.FilterState() != list.Filtering {
+			m.openPalette()
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Quit) && m.list.FilterState() != list.Filtering {
+			slog.Debug("quit", "key", msg.String())
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+		if key.Matches(msg, m.keys.Narrower, m.keys.Wider) && m.list.FilterState() != list.Filtering {
+			if key.Matches(msg, m.keys.Narrower) {
+				m.shiftSplit(-splitStep)
+			} else {
+				m.shiftSplit(splitStep)
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.ExportTree) && m.list.FilterState() != list.Filtering {
+			if m.exportTree() {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Copy, m.keys.Move, m.keys.Paste) && m.list.FilterState() != list.Filtering {
+			if key.Matches(msg, m.keys.Paste) {
+				m.paste()
+			} else {
+				m.markFile(key.Matches(msg, m.keys.Move))
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.ToggleHidden) && m.list.FilterState() != list.Filtering {
+			m.toggleHidden()
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Bookmark, m.keys.Bookmarks) && m.list.FilterState() != list.Filtering {
+			if key.Matches(msg, m.keys.Bookmark) {
+				m.toggleBookmark()
+			} else {
+				m.openBookmarks()
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Undo) && m.list.FilterState() != list.Filtering {
+			m.undo()
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Mark, m.keys.MarkRange) && m.list.FilterState() != list.Filtering {
+			if key.Matches(msg, m.keys.Mark) {
+				m.toggleMark()
+			} else {
+				m.markRange()
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, m.keys.Complete) && m.list.FilterState() == list.Filtering {
+			m.completeFilter()
+			return m, nil
+		}
+
+		// o picks the file like enter but prints its directory as well,
+		// for shell wrappers that open the file and cd next to it.
+		if key.Matches(msg, m.keys.SelectWithDir) && m.list.FilterState() != list.Filtering {
+			if i, ok := m.list.SelectedItem().(item); ok && !i.isDir {
+				slog.Info("selected", "path", i.path, "print_dir", true, "marked", len(m.selected))
+				m.selectedFile = i.path
+				m.printBoth = true
+				if len(m.selected) > 0 {
+					m.chosen = m.markedPaths()
+				}
+				return m, tea.Quit
+			}
+		}
+
+		// In -dirs-only mode enter only navigates; "." picks the directory
+		// being shown.
+		if key.Matches(msg, m.keys.ChooseDir) && m.opts.dirsOnly && m.list.FilterState() != list.Filtering {
+			slog.Info("selected", "path", m.currentDir)
+			m.selectedFile = m.currentDir
+			return m, tea.Quit
+		}
+
+		if key.Matches(msg, m.keys.Select) {
 			i, ok := m.list.SelectedItem().(item)
 			if ok {
 				if i.isDir {
-					m.currentDir = i.path
-					m.list.SetItems(getItems(m.currentDir))
-					m.list.ResetFilter()
+					if m.changeDir(i.path) {
+						m.list.ResetFilter()
+					}
 					return m, nil
 				} else {
+					slog.Info("selected", "path", i.path, "marked", len(m.selected))
 					m.selectedFile = i.path
+					// With files marked, enter takes them rather than
+					// the highlighted one.
+					if len(m.selected) > 0 {
+						m.chosen = m.markedPaths()
+					}
 					return m, tea.Quit
 				}
 			}
 		}
 
 	case tea.WindowSizeMsg:
-		h, v := docStyle.GetFrameSize()
 		m.width = msg.Width
-		// If we are in AltScreen, we use the full height.
-		// If not, we might use a fixed height.
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.height = msg.Height
+		m.resize()
+
+	case walkProgressMsg:
+		if msg.walk != m.walk {
+			return m, nil
+		}
+		m.notice = fmt.Sprintf("Scanning… %d dirs, %d files (esc to cancel)", msg.dirs, msg.files)
+		return m, waitForWalk(m.walk)
+
+	case walkDoneMsg:
+		if msg.walk != m.walk {
+			return m, nil
+		}
+		m.walk = nil
+		m.cycles = append(m.cycles, msg.cycles...)
+		m.list.SetItems(m.listing(msg.walk.dir, msg.items))
+		m.notice = ""
+		if msg.cancelled {
+			m.notice = fmt.Sprintf("Scan cancelled: showing the %d entries found", len(msg.items))
+		}
+		slog.Debug("walk done", "dir", msg.walk.dir, "entries", len(msg.items), "cancelled", msg.cancelled)
+		return m, nil
+
+	case contentResultsMsg:
+		m.showContentResults(msg)
+		return m, nil
+
+	case list.FilterMatchesMsg:
+		if m.palette != nil {
+			var cmd tea.Cmd
+			_, _, cmd = m.palette.Update(msg)
+			return m, cmd
+		}
+		if m.bookmarks != nil {
+			var cmd tea.Cmd
+			m.bookmarks.list, cmd = m.bookmarks.list.Update(msg)
+			return m, cmd
+		}
+
+	case filterTickMsg:
+		if int(msg) == m.filterSeq && m.list.FilterState() == list.Filtering {
+			m.list.SetFilterText(m.list.FilterValue())
+			m.list.SetFilterState(list.Filtering)
+		}
+		return m, nil
 	}
 
 	var cmd tea.Cmd
+	query := m.list.FilterValue()
 	m.list, cmd = m.list.Update(msg)
+	if m.list.FilterState() == list.Filtering && m.list.FilterValue() != query {
+		// Drop the list's immediate re-filter and debounce it instead.
+		m.filterSeq++
+		return m, debounceFilter(m.filterSeq)
+	}
 	return m, cmd
 }
 
+// completeFilter extends the filter query to the longest common prefix of
+// the entries whose names start with it, shell-style. When the query can't
+// be extended and the only match is a directory, it descends into that
+// directory and keeps filtering there.
+func (m *model) completeFilter() {
+	query := m.list.FilterValue()
+
+	var candidates []item
+	for _, li := range m.list.Items() {
+		i, ok := li.(item)
+		if !ok || i.title == ".." {
+			continue
+		}
+		if hasPrefixFold(filepath.Base(i.path), query) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	prefix := filepath.Base(candidates[0].path)
+	for _, c := range candidates[1:] {
+		prefix = commonPrefixFold(prefix, filepath.Base(c.path))
+	}
+
+	if len(prefix) > len(query) {
+		m.list.SetFilterText(prefix)
+		m.list.SetFilterState(list.Filtering)
+		return
+	}
+
+	if len(candidates) == 1 && candidates[0].isDir && m.changeDir(candidates[0].path) {
+		m.list.SetFilterText("")
+		m.list.SetFilterState(list.Filtering)
+	}
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// commonPrefixFold returns the longest case-insensitive common prefix of a
+// and b, keeping the casing of a.
+func commonPrefixFold(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+	for n < len(ar) && n < len(br) && strings.EqualFold(string(ar[n]), string(br[n])) {
+		n++
+	}
+	return string(ar[:n])
+}
+
 func (m model) View() string {
 	if m.quitting || m.selectedFile != "" {
 		return ""
 	}
-	return docStyle.Render(m.list.View())
+	body := m.list.View()
+	if m.contentMode {
+		body = m.contentInput.View() + "\n" + body
+	}
+	body = lipgloss.JoinHorizontal(lipgloss.Top, body, zone.Mark(previewZone, previewStyle.Render(m.preview.View())))
+	if m.palette != nil {
+		body = m.palette.View()
+	}
+	if m.bookmarks != nil {
+		body = m.bookmarks.list.View()
+	}
+	footer := errorStyle.Render(m.err)
+	if m.err == "" && m.notice != "" {
+		footer = noticeStyle.Render(m.notice)
+	} else if m.err == "" {
+		footer = noticeStyle.Render(m.clipboardStatus())
+		if plain.Enabled() && m.clipboardStatus() == "" {
+			footer = m.announcement()
+		}
+	}
+	return zone.Scan(docStyle.Render(body + "\n" + footer))
+}
+
+// resize fits the list into the window, leaving room for the footer and,
+// while searching file contents, the query line.
+func (m *model) resize() {
+	h, v := docStyle.GetFrameSize()
+	rows := m.height - v - footerHeight
+	listRows := rows
+	if m.contentMode {
+		listRows--
+	}
+
+	width := m.width - h
+	listWidth := int(float64(width) * m.split)
+	m.list.SetSize(listWidth, listRows)
+	m.contentInput.Width = listWidth - lipgloss.Width(m.contentInput.Prompt) - 1
+
+	m.preview.Width = max(width-listWidth-previewStyle.GetHorizontalFrameSize(), 0)
+	m.preview.Height = max(rows, 0)
+	if m.palette != nil {
+		m.palette.SetSize(width, max(rows, 0))
+	}
+	if m.bookmarks != nil {
+		m.bookmarks.list.SetSize(width, max(rows, 0))
+	}
 }
 
 func main() {
 	var heightFlag int
+	var pickerOpts options
+	var filter filterFlag
 	flag.IntVar(&heightFlag, "height", 0, "Height of the picker (default: full screen)")
+	flag.BoolVar(&pickerOpts.recursive, "recursive", false, "List files in all subdirectories")
+	flag.IntVar(&pickerOpts.maxDepth, "max-depth", defaultMaxDepth, "How many directory levels -recursive descends: 0 lists only the start directory, negative has no limit")
+	flag.BoolVar(&pickerOpts.followSymlinks, "follow-symlinks", false, "Descend into symlinked directories when recursive")
+	flag.BoolVar(&pickerOpts.verbose, "verbose", false, "Log to $XDG_STATE_HOME/aign/aign.log and report skipped symlink cycles on stderr after exit")
+	flag.BoolVar(&pickerOpts.dirsOnly, "dirs-only", false, "List only directories; press . to choose the current one")
+	flag.BoolVar(&pickerOpts.recent, "recent", false, "List files chosen through the picker before first, most recent at the top")
+	flag.BoolVar(&pickerOpts.printDir, "print-dir", false, "Print the selected file's parent directory instead of its path")
+	flag.IntVar(&pickerOpts.minFilterLen, "min-filter", 1, "Minimum query length before the filter narrows the list")
+	flag.StringVar(&pickerOpts.treeOut, "tree-out", "", "File ctrl+e writes the directory tree to (default: print it on exit)")
+	flag.BoolVar(&pickerOpts.git, "git", false, "Mark files with their git status (M, A, R, ??) inside a repository")
+	plainFlag := flag.Bool("plain", false, "Screen reader mode: no colour, emoji, borders or mouse, with the highlighted entry named in the footer")
+	flag.BoolVar(&pickerOpts.print0, "print0", false, "End each printed path with a NUL byte instead of a newline, for xargs -0")
+	stdin := flag.Bool("stdin", false, "Pick from lines read on stdin instead of files, like fzf; enter prints the chosen line")
+	// Space marks files in every run now; -multi is only kept so that
+	// scripts passing it are told rather than refused.
+	multi := flag.Bool("multi", false, "Deprecated: space marks files in every run (ctrl+space marks the range from the last one)")
+	var minSize, maxSize sizeFlag
+	flag.Var(&minSize, "min-size", "List only files of at least this size, e.g. 10M (K, M, G, T in 1024s); folders are always listed")
+	flag.Var(&maxSize, "max-size", "List only files of at most this size, e.g. 1G")
+	ext := flag.String("ext", "", "List only files with these extensions, comma-separated, e.g. md,txt,pdf; folders are always listed")
+	flag.StringVar(&pickerOpts.glob, "glob", "", "List only files whose names match this pattern, e.g. \"*.json\"; with -ext, files must match both")
+	dir := flag.String("dir", "", "Directory to start in (default: ~/Downloads, or $HOME without one)")
+	cwd := flag.Bool("cwd", false, "Start in the current working directory")
+	flag.Var(&filter, "filter", "Start with the filter open, optionally with a query: -filter resume")
 	flag.Parse()
+	if filter.set && filter.query == "" && flag.NArg() > 0 {
+		filter.query = flag.Arg(0)
+	}
+	pickerOpts.minSize, pickerOpts.maxSize = int64(minSize), int64(maxSize)
+	pickerOpts.exts = parseExts(*ext)
+	if _, err := filepath.Match(pickerOpts.glob, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "-glob %q: %v\n", pickerOpts.glob, err)
+		os.Exit(2)
+	}
+	if pickerOpts.maxSize > 0 && pickerOpts.minSize > pickerOpts.maxSize {
+		fmt.Fprintln(os.Stderr, "-min-size is larger than -max-size")
+		os.Exit(2)
+	}
+	if *multi {
+		fmt.Fprintln(os.Stderr, "Warning: -multi is deprecated and does nothing; space marks files in every run")
+	}
+	if *plainFlag {
+		plain.Enable()
+		applyPlain()
+	}
 
-	home, _ := os.UserHomeDir()
-	startDir := filepath.Join(home, "Downloads")
-	if _, err := os.Stat(startDir); err != nil {
-		startDir = home
+	closeLog, err := logging.Setup("picker", pickerOpts.verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging disabled: %v\n", err)
 	}
+	defer closeLog()
 
-	items := getItems(startDir)
-	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "CAREER AI: SELECT FILE"
-	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
+	start, err := startDir(*dir, *cwd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	m := model{
-		list:       l,
-		currentDir: startDir,
+	if pickerOpts.recent {
+		pickerOpts.lastOpened = loadRecent()
+	}
+	if pickerOpts.bookmarks, err = loadBookmarks(); err != nil {
+		slog.Warn("load bookmarks", "err", err)
+		fmt.Fprintf(os.Stderr, "Warning: bookmarks not loaded: %v\n", err)
+	}
+	if *stdin {
+		if !stdinPiped() {
+			fmt.Fprintln(os.Stderr, "-stdin needs lines piped in")
+			os.Exit(2)
+		}
+		if pickerOpts.lines, err = readLines(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(2)
+		}
+		if pickerOpts.lines == nil {
+			pickerOpts.lines = []string{}
+		}
 	}
 
+	keys := defaultKeyMap()
+	if err := config.LoadKeys("picker", keys.actions()); err != nil {
+		path, _ := config.Path()
+		fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+		os.Exit(2)
+	}
+
+	m := newModel(start, pickerOpts)
+	m.setKeys(keys)
+	if filter.set {
+		m.startFiltering(filter.query)
+	}
+	config.Value(splitConfigKey, &m.split)
+	m.split = min(max(m.split, minSplit), maxSplit)
+
 	// Open TTY for TUI communication
 	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
@@ -149,27 +795,96 @@ func main() {
 		f = os.Stderr
 	}
 	defer f.Close()
+	defer terminal.RestoreOnPanic(f)
 
 	opts := []tea.ProgramOption{
 		tea.WithInput(f),
 		tea.WithOutput(f),
 	}
 
-	// If height is 0, use AltScreen (full terminal)
+	// If height is 0, use AltScreen (full terminal). Only there do mouse
+	// positions line up with the view, so the wheel can find the preview;
+	// -plain keeps to the keyboard.
 	if heightFlag == 0 {
 		opts = append(opts, tea.WithAltScreen())
+		if !*plainFlag {
+			opts = append(opts, tea.WithMouseCellMotion())
+		}
 	}
+	zone.NewGlobal()
 
 	p := tea.NewProgram(m, opts...)
 
 	finalModel, err := p.Run()
 	if err != nil {
+		slog.Error("program failed", "err", err)
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if fm, ok := finalModel.(model); ok && fm.selectedFile != "" {
+	fm, ok := finalModel.(model)
+	if ok && fm.split != m.split {
+		if err := config.SetValue(splitConfigKey, fm.split); err != nil {
+			slog.Error("save split ratio", "err", err)
+			fmt.Fprintf(os.Stderr, "Warning: could not save split ratio: %v\n", err)
+		}
+	}
+	if ok && fm.opts.verbose {
+		for _, path := range fm.cycles {
+			slog.Info("skipped symlink cycle", "path", path)
+			fmt.Fprintf(os.Stderr, "skipped symlink to visited directory: %s\n", path)
+		}
+	}
+
+	if ok && fm.tree != "" {
+		fmt.Print(fm.tree)
+		return
+	}
+
+	if ok && fm.selectedFile != "" {
+		// Lines from stdin needn't be files, so they aren't remembered.
+		if !*stdin {
+			picked := fm.chosen
+			if len(picked) == 0 {
+				picked = []string{fm.selectedFile}
+			}
+			for _, path := range picked {
+				if err := recordRecent(path); err != nil {
+					slog.Error("record selection", "err", err)
+				}
+			}
+		}
+
 		// Output ONLY the final path to stdout
-		fmt.Println(fm.selectedFile)
+		fm.printSelection(os.Stdout)
+	}
+}
+
+// printSelection writes each chosen path, or its directory, or both, each
+// ended by a newline or with -print0 by a NUL, as find -print0 does. The
+// marked files are chosen when enter or o took them, else the selected one.
+func (m model) printSelection(w io.Writer) {
+	picked := m.chosen
+	if len(picked) == 0 {
+		picked = []string{m.selectedFile}
+	}
+	var paths []string
+	for _, p := range picked {
+		switch {
+		case m.printBoth:
+			paths = append(paths, p, filepath.Dir(p))
+		case m.opts.printDir:
+			paths = append(paths, filepath.Dir(p))
+		default:
+			paths = append(paths, p)
+		}
+	}
+
+	end := "\n"
+	if m.opts.print0 {
+		end = "\x00"
+	}
+	for _, p := range paths {
+		fmt.Fprint(w, p+end)
 	}
 }
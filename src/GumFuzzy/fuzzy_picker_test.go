@@ -0,0 +1,962 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"aign/render/palette"
+	"aign/render/plain"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/exp/teatest"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestMain(m *testing.M) {
+	zone.NewGlobal()
+	os.Exit(m.Run())
+}
+
+func setupTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.md", filepath.Join("sub", "note.txt")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("hello\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runPicker(t *testing.T, m model, keys ...tea.KeyMsg) model {
+	t.Helper()
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(80, 24))
+	for _, k := range keys {
+		tm.Send(k)
+	}
+	fm, ok := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if !ok {
+		t.Fatalf("final model has unexpected type")
+	}
+	return fm
+}
+
+func TestPickerNavigateAndSelect(t *testing.T) {
+	dir := setupTree(t)
+
+	// Entries are "..", "a.md", "sub": move to "sub", enter it, then pick
+	// the file below its ".." entry.
+	fm := runPicker(t, newModel(dir, options{}),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyEnter},
+	)
+
+	if want := filepath.Join(dir, "sub"); fm.currentDir != want {
+		t.Errorf("currentDir = %q, want %q", fm.currentDir, want)
+	}
+	if want := filepath.Join(dir, "sub", "note.txt"); fm.selectedFile != want {
+		t.Errorf("selectedFile = %q, want %q", fm.selectedFile, want)
+	}
+}
+
+func TestPickerQuitWithoutSelection(t *testing.T) {
+	dir := setupTree(t)
+
+	fm := runPicker(t, newModel(dir, options{}),
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")},
+	)
+
+	if !fm.quitting {
+		t.Error("expected quitting to be set")
+	}
+	if fm.selectedFile != "" {
+		t.Errorf("selectedFile = %q, want empty", fm.selectedFile)
+	}
+}
+
+func TestPickerQuitKeyWhileFiltering(t *testing.T) {
+	dir := setupTree(t)
+	m := newModel(dir, options{})
+	m.startFiltering("")
+
+	// q is part of the query while filtering; only ctrl+c quits.
+	fm := runPicker(t, m,
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")},
+		tea.KeyMsg{Type: tea.KeyCtrlC},
+	)
+
+	if got := fm.list.FilterValue(); got != "qu" {
+		t.Errorf("filter = %q, want %q", got, "qu")
+	}
+}
+
+func TestPickerDirsOnly(t *testing.T) {
+	dir := setupTree(t)
+
+	// Only ".." and "sub" are listed: enter "sub", then choose it.
+	fm := runPicker(t, newModel(dir, options{dirsOnly: true}),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")},
+	)
+
+	if want := filepath.Join(dir, "sub"); fm.selectedFile != want {
+		t.Errorf("selectedFile = %q, want %q", fm.selectedFile, want)
+	}
+}
+
+func TestPickerStartFiltering(t *testing.T) {
+	dir := setupTree(t)
+	m := newModel(dir, options{minFilterLen: 1})
+	m.startFiltering("a.m")
+
+	fm := runPicker(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if want := filepath.Join(dir, "a.md"); fm.selectedFile != want {
+		t.Errorf("selectedFile = %q, want %q", fm.selectedFile, want)
+	}
+}
+
+func TestPickerCompleteFilter(t *testing.T) {
+	dir := setupTree(t)
+
+	// "sub" can't be extended and is the only match: tab enters it.
+	m := newModel(dir, options{})
+	m.startFiltering("sub")
+	m.completeFilter()
+	if want := filepath.Join(dir, "sub"); m.currentDir != want {
+		t.Errorf("currentDir = %q, want %q", m.currentDir, want)
+	}
+
+	// With sub.md matching as well, tab stays put.
+	if err := os.WriteFile(filepath.Join(dir, "sub.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m = newModel(dir, options{})
+	m.startFiltering("sub")
+	m.completeFilter()
+	if m.currentDir != dir {
+		t.Errorf("currentDir = %q, want %q", m.currentDir, dir)
+	}
+}
+
+func TestPickerRecursiveWalk(t *testing.T) {
+	dir := setupTree(t)
+
+	tm := teatest.NewTestModel(t, newModel(dir, options{recursive: true, maxDepth: defaultMaxDepth}), teatest.WithInitialTermSize(80, 24))
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("note.txt"))
+	}, teatest.WithDuration(3*time.Second))
+
+	// Entries are "..", "a.md", "sub", "sub/note.txt".
+	for range 3 {
+		tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if want := filepath.Join(dir, "sub", "note.txt"); fm.selectedFile != want {
+		t.Errorf("selectedFile = %q, want %q", fm.selectedFile, want)
+	}
+	if fm.walk != nil {
+		t.Error("walk still marked as running")
+	}
+}
+
+func TestPickerCopyAndMove(t *testing.T) {
+	dir := setupTree(t)
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	// Copy a.md into sub, then back into its own directory, where the
+	// name is taken and r keeps both.
+	fm := runPicker(t, newModel(dir, options{}),
+		tea.KeyMsg{Type: tea.KeyDown},
+		runes("c"),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		runes("p"),
+		tea.KeyMsg{Type: tea.KeyHome},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		runes("p"),
+		runes("r"),
+		runes("q"),
+	)
+	for _, name := range []string{"a.md", filepath.Join("sub", "a.md"), "a (1).md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("after copy: %v", err)
+		}
+	}
+	if fm.clip == nil {
+		t.Error("copied file should stay on the clipboard")
+	}
+
+	// Move sub/note.txt up a level.
+	fm = runPicker(t, newModel(filepath.Join(dir, "sub"), options{}),
+		tea.KeyMsg{Type: tea.KeyEnd},
+		runes("m"),
+		tea.KeyMsg{Type: tea.KeyHome},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		runes("p"),
+		runes("q"),
+	)
+	if _, err := os.Stat(filepath.Join(dir, "note.txt")); err != nil {
+		t.Errorf("after move: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub", "note.txt")); !os.IsNotExist(err) {
+		t.Errorf("moved file still in sub: %v", err)
+	}
+	if fm.clip != nil {
+		t.Error("clipboard should be empty after a move")
+	}
+}
+
+func TestPickerGitStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := setupTree(t)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "a.md")
+	git("commit", "-qm", "init")
+	for name, body := range map[string]string{"a.md": "changed\n", "b.md": "new\n", "c.md": "staged\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", "c.md")
+	// A link is tracked as itself, not as the file it points to.
+	if err := os.Symlink("a.md", filepath.Join(dir, "l.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	// git reports paths with symlinks resolved, so listing the repo
+	// through a link to it must still find each file's status.
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a.md": "M", "b.md": "??", "c.md": "A", "l.md": "??", "sub": "??"}
+	for _, start := range []string{dir, link} {
+		m := newModel(start, options{git: true})
+		got := make(map[string]string)
+		for _, li := range m.list.Items() {
+			i := li.(item)
+			got[filepath.Base(i.path)] = i.git
+		}
+		for name, code := range want {
+			if got[name] != code {
+				t.Errorf("%s: %s status = %q, want %q", start, name, got[name], code)
+			}
+		}
+	}
+
+	if m := newModel(t.TempDir(), options{git: true}); len(m.list.Items()) != 1 {
+		t.Errorf("outside a repo the listing should just be .., got %d items", len(m.list.Items()))
+	}
+}
+
+func TestPickerScrollPreview(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "long.md"), []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tm tea.Model = newModel(dir, options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if got := tm.(model).previewPath; filepath.Base(got) != "long.md" {
+		t.Fatalf("preview shows %q, want long.md", got)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	m := tm.(model)
+	if m.preview.YOffset == 0 {
+		t.Fatal("ctrl+d should scroll the preview")
+	}
+	if i, _ := m.list.SelectedItem().(item); filepath.Base(i.path) != "long.md" {
+		t.Errorf("scrolling the preview moved the selection to %q", i.path)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	if off := tm.(model).preview.YOffset; off != 0 {
+		t.Errorf("ctrl+u should scroll back to the top, offset %d", off)
+	}
+
+	// The wheel scrolls the preview only when over it.
+	tm.View()
+	z := waitForZone(t, previewZone)
+	wheel := func(x int) int {
+		tm, _ = tm.Update(tea.MouseMsg{X: x, Y: z.StartY + 2, Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+		return tm.(model).preview.YOffset
+	}
+	if off := wheel(z.StartX - 10); off != 0 {
+		t.Errorf("wheel over the list scrolled the preview to %d", off)
+	}
+	if off := wheel(z.StartX + 5); off == 0 {
+		t.Error("wheel over the preview should scroll it")
+	}
+}
+
+func waitForZone(t *testing.T, id string) *zone.ZoneInfo {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if z := zone.Get(id); z != nil && !z.IsZero() {
+			return z
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("zone %q never rendered", id)
+	return nil
+}
+
+func TestPrintSelection(t *testing.T) {
+	m := model{selectedFile: "/tmp/my dir/cover\nletter.md"}
+	tests := []struct {
+		both, dir, print0 bool
+		want              string
+	}{
+		{want: "/tmp/my dir/cover\nletter.md\n"},
+		{print0: true, want: "/tmp/my dir/cover\nletter.md\x00"},
+		{dir: true, print0: true, want: "/tmp/my dir\x00"},
+		{both: true, print0: true, want: "/tmp/my dir/cover\nletter.md\x00/tmp/my dir\x00"},
+	}
+	for _, tt := range tests {
+		m.printBoth, m.opts.printDir, m.opts.print0 = tt.both, tt.dir, tt.print0
+		var buf bytes.Buffer
+		m.printSelection(&buf)
+		if buf.String() != tt.want {
+			t.Errorf("printSelection(both=%v, dir=%v, print0=%v) = %q, want %q", tt.both, tt.dir, tt.print0, buf.String(), tt.want)
+		}
+	}
+
+	// Marked files each get the same treatment.
+	m = model{selectedFile: "/c/d.md", chosen: []string{"/a/b.md", "/c/d.md"}}
+	m.printBoth = true
+	var buf bytes.Buffer
+	m.printSelection(&buf)
+	if want := "/a/b.md\n/a\n/c/d.md\n/c\n"; buf.String() != want {
+		t.Errorf("printSelection(marked, both) = %q, want %q", buf.String(), want)
+	}
+	m.printBoth, m.opts.printDir = false, true
+	buf.Reset()
+	m.printSelection(&buf)
+	if want := "/a\n/c\n"; buf.String() != want {
+		t.Errorf("printSelection(marked, dir) = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPickerPlain(t *testing.T) {
+	savedStyles := []lipgloss.Style{errorStyle, noticeStyle, previewStyle}
+	savedGit := maps.Clone(gitStatusStyles)
+	t.Cleanup(func() {
+		errorStyle, noticeStyle, previewStyle = savedStyles[0], savedStyles[1], savedStyles[2]
+		gitStatusStyles = savedGit
+		markIcon, bookmarkIcon = "✓ ", "⭐ "
+	})
+	t.Cleanup(plain.Enable())
+	applyPlain()
+
+	var tm tea.Model = newModel(setupTree(t), options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view := tm.View()
+	if !strings.Contains(view, "Highlighted a.md, file 2 of 3") {
+		t.Errorf("footer should name the highlighted entry:\n%s", view)
+	}
+	if !strings.Contains(view, "> a.md") {
+		t.Errorf("the highlighted entry should be pointed at with >:\n%s", view)
+	}
+	for _, r := range view {
+		if r == '\x1b' || r >= 0x2500 {
+			t.Fatalf("plain view contains %q:\n%s", r, view)
+		}
+	}
+}
+
+func TestPickerMultiRange(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.md", "c.md", "d.md", "e.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+
+	// Entries are "..", a.md … e.md. Mark e.md on its own, then a.md as
+	// the anchor and ctrl+space on c.md to take b.md and c.md with it.
+	fm := runPicker(t, newModel(dir, options{}),
+		down, down, down, down, down, space,
+		up, up, up, up, space,
+		down, down, tea.KeyMsg{Type: tea.KeyCtrlAt},
+		tea.KeyMsg{Type: tea.KeyEnter},
+	)
+
+	var want []string
+	for _, name := range []string{"a.md", "b.md", "c.md", "e.md"} {
+		want = append(want, filepath.Join(dir, name))
+	}
+	if !slices.Equal(fm.chosen, want) {
+		t.Errorf("chosen = %q, want %q", fm.chosen, want)
+	}
+	var buf bytes.Buffer
+	fm.printSelection(&buf)
+	if got := strings.Fields(buf.String()); !slices.Equal(got, want) {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
+
+func TestPickerMultiSkipsDirs(t *testing.T) {
+	dir := setupTree(t)
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+
+	// Entries are "..", "a.md", "sub": mark a.md, try to mark sub, then
+	// enter sub and mark the file inside it too.
+	fm := runPicker(t, newModel(dir, options{}),
+		down, space, down, space,
+		tea.KeyMsg{Type: tea.KeyEnter},
+		down, space,
+		tea.KeyMsg{Type: tea.KeyEnter},
+	)
+
+	want := []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "sub", "note.txt")}
+	if !slices.Equal(fm.chosen, want) {
+		t.Errorf("chosen = %q, want %q", fm.chosen, want)
+	}
+
+	// o takes the marks too, printing each with its directory.
+	fm = runPicker(t, newModel(dir, options{}),
+		down, space,
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")},
+	)
+	if want := []string{filepath.Join(dir, "a.md")}; !slices.Equal(fm.chosen, want) || !fm.printBoth {
+		t.Errorf("o: chosen = %q, printBoth = %v", fm.chosen, fm.printBoth)
+	}
+}
+
+func TestPickerStdin(t *testing.T) {
+	lines, err := readLines(strings.NewReader("alpha\r\n\n  \nbeta gamma\ndelta\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alpha", "beta gamma", "delta"}; !slices.Equal(lines, want) {
+		t.Fatalf("readLines = %q, want %q", lines, want)
+	}
+
+	// No ".." entry: the second line is one step down.
+	m := newModel(t.TempDir(), options{lines: lines})
+	if m, _ := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}); m.err == "" {
+		t.Errorf("copying a stdin line gave no error")
+	}
+	fm := runPicker(t, m,
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyEnter},
+	)
+	if fm.selectedFile != "beta gamma" {
+		t.Errorf("selectedFile = %q, want %q", fm.selectedFile, "beta gamma")
+	}
+}
+
+func TestWalkMaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	deep := filepath.Join(dir, "a", "b", "c")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "top.md"), filepath.Join(deep, "deep.md")} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{0, []string{"a", "top.md"}},
+		{1, []string{"a", "a/b", "top.md"}},
+		{-1, []string{"a", "a/b", "a/b/c", "a/b/c/deep.md", "top.md"}},
+	}
+	for _, tt := range tests {
+		items, _ := walkTree(context.Background(), dir, options{maxDepth: tt.depth}, nil)
+		var got []string
+		for _, li := range items {
+			rel, _ := filepath.Rel(dir, li.(item).path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("maxDepth %d: %q, want %q", tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestWalkLinkToLaterSibling(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b", "note.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "b"), filepath.Join(dir, "a")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The link a is walked before the real b, whose contents it has
+	// already listed.
+	items, _ := walkTree(context.Background(), dir, options{followSymlinks: true, maxDepth: -1}, nil)
+	var got []string
+	for _, li := range items {
+		rel, _ := filepath.Rel(dir, li.(item).path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	if want := []string{"a", "a/note.md", "b"}; !slices.Equal(got, want) {
+		t.Errorf("walk = %q, want %q", got, want)
+	}
+}
+
+func TestPickerSizeRange(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int64
+	}{{"512", 512}, {"10K", 10 << 10}, {"10m", 10 << 20}, {"1.5G", 3 << 29}, {"2GiB", 2 << 30}, {"1 MB", 1 << 20}} {
+		if got, err := parseSize(tt.in); err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "M", "ten", "-1K"} {
+		if _, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q) accepted", bad)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"tiny.md": 10, "mid.pdf": 2 << 10, "big.zip": 8 << 10} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newModel(dir, options{minSize: 1 << 10, maxSize: 4 << 10})
+	var got []string
+	for _, li := range m.list.Items() {
+		got = append(got, filepath.Base(li.(item).path))
+	}
+	if want := []string{filepath.Base(filepath.Dir(dir)), "mid.pdf", "sub"}; !slices.Equal(got, want) {
+		t.Errorf("listed %q, want %q", got, want)
+	}
+	if title := m.title(); !strings.HasSuffix(title, " • 1K–4K") {
+		t.Errorf("title = %q", title)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	for _, tt := range []struct {
+		in   int64
+		want string
+	}{{0, "0 B"}, {1023, "1023 B"}, {1 << 10, "1.0 KB"}, {2516582, "2.4 MB"}, {3 << 29, "1.5 GB"}, {1 << 40, "1.0 TB"}} {
+		if got := humanSize(tt.in); got != tt.want {
+			t.Errorf("humanSize(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	dir := setupTree(t)
+	if err := os.Mkdir(filepath.Join(dir, "locked"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "locked"), 0o755) })
+	got := make(map[string]string)
+	for _, li := range newModel(dir, options{}).list.Items() {
+		i := li.(item)
+		_, got[filepath.Base(i.path)], _ = strings.Cut(i.desc, " | ")
+	}
+	want := map[string]string{"a.md": "6 B", "sub": "1 item"}
+	if os.Geteuid() != 0 {
+		want["locked"] = "—"
+	}
+	for name, desc := range want {
+		if got[name] != desc {
+			t.Errorf("%s described as %q, want %q", name, got[name], desc)
+		}
+	}
+
+	// The walk doesn't read every folder again just to count it.
+	items, _ := walkTree(context.Background(), dir, options{maxDepth: -1}, nil)
+	for _, li := range items {
+		if i := li.(item); i.isDir && strings.Contains(i.desc, "|") {
+			t.Errorf("walked folder %s described as %q, want no count", i.path, i.desc)
+		}
+	}
+}
+
+func TestPickerNameFilter(t *testing.T) {
+	if got := parseExts(" md, .TXT,,pdf"); !slices.Equal(got, []string{"md", "txt", "pdf"}) {
+		t.Errorf("parseExts = %q", got)
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Resume.MD", "notes.txt", "data.json", "resume.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := func(opts options) []string {
+		var got []string
+		for _, li := range newModel(dir, opts).list.Items() {
+			got = append(got, filepath.Base(li.(item).path))
+		}
+		return got
+	}
+	parent := filepath.Base(filepath.Dir(dir))
+
+	for _, tt := range []struct {
+		opts options
+		want []string
+	}{
+		{options{exts: []string{"md", "txt"}}, []string{parent, "Resume.MD", "notes.txt", "sub.d"}},
+		{options{glob: "*.JSON"}, []string{parent, "data.json", "sub.d"}},
+		{options{exts: []string{"pdf", "md"}, glob: "resume*"}, []string{parent, "Resume.MD", "resume.pdf", "sub.d"}},
+	} {
+		if got := list(tt.opts); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v listed %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+
+	m := newModel(dir, options{exts: []string{"md", "txt"}, glob: "*e*"})
+	if title := m.title(); !strings.HasSuffix(title, " • md, txt + *e*") {
+		t.Errorf("title = %q", title)
+	}
+}
+
+func TestPickerUndo(t *testing.T) {
+	dir := setupTree(t)
+	sub := filepath.Join(dir, "sub")
+	undo := func(m model) model {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+		return next.(model)
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	m := newModel(sub, options{})
+	m.clip = &clipboard{path: filepath.Join(dir, "a.md")}
+	m.paste()
+	m.clip = &clipboard{path: filepath.Join(sub, "note.txt"), move: true}
+	m.changeDir(dir)
+	m.paste()
+	if !exists(filepath.Join(sub, "a.md")) || !exists(filepath.Join(dir, "note.txt")) {
+		t.Fatal("pastes didn't happen")
+	}
+
+	// Last first: the move goes back, then the copy is removed.
+	m = undo(m)
+	if !exists(filepath.Join(sub, "note.txt")) || exists(filepath.Join(dir, "note.txt")) {
+		t.Errorf("move not undone: %s", m.err)
+	}
+	m = undo(m)
+	if exists(filepath.Join(sub, "a.md")) || !exists(filepath.Join(dir, "a.md")) {
+		t.Errorf("copy not undone: %s", m.err)
+	}
+	if m = undo(m); m.err != "Nothing to undo" {
+		t.Errorf("err = %q", m.err)
+	}
+
+	// Overwriting can't be taken back.
+	m.clip = &clipboard{path: filepath.Join(dir, "a.md")}
+	m.changeDir(sub)
+	m.finishPaste(filepath.Join(sub, "note.txt"))
+	if m = undo(m); !strings.HasPrefix(m.err, "Can't undo the copy") {
+		t.Errorf("err = %q", m.err)
+	}
+	if !exists(filepath.Join(sub, "note.txt")) {
+		t.Error("undo removed the overwritten file")
+	}
+
+	// Nor can the copy it wrote over: that is forgotten rather than
+	// removing the newer file.
+	m.clip = &clipboard{path: filepath.Join(dir, "a.md")}
+	m.paste()
+	m.finishPaste(filepath.Join(sub, "a.md"))
+	if m = undo(m); !strings.HasPrefix(m.err, "Can't undo the copy") {
+		t.Errorf("err = %q", m.err)
+	}
+	if m = undo(m); m.err != "Nothing to undo" || !exists(filepath.Join(sub, "a.md")) {
+		t.Errorf("undo went back past the overwrite: %q", m.err)
+	}
+
+	// A copy edited since it was pasted is left alone.
+	if err := os.Remove(filepath.Join(sub, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	m.paste()
+	if err := os.WriteFile(filepath.Join(sub, "a.md"), []byte("edited since"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if m = undo(m); !strings.Contains(m.err, "has changed since") || !exists(filepath.Join(sub, "a.md")) {
+		t.Errorf("undo removed an edited copy: %q", m.err)
+	}
+}
+
+func TestPickerPalette(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var tm tea.Model = newModel(setupTree(t), options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p"), Alt: true})
+	m := tm.(model)
+	if m.palette == nil {
+		t.Fatal("alt+p didn't open the palette")
+	}
+	view := m.View()
+	if !strings.Contains(view, "export tree") || !strings.Contains(view, "ctrl+e") {
+		t.Errorf("palette doesn't list actions with their keys:\n%s", view)
+	}
+	if strings.Contains(view, "choose this folder") {
+		t.Error("palette offers choosing a folder without -dirs-only")
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = tm.(model); m.palette != nil {
+		t.Error("esc didn't close the palette")
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyF1})
+	if m = tm.(model); m.palette == nil {
+		t.Fatal("f1 didn't open the palette")
+	}
+
+	// Filtering and choosing are the shared palette's own; what the
+	// picker adds is running the chosen action once it has closed.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m, _ = tm.(model).runAction(m.keys.Narrower); m.split >= defaultSplit {
+		t.Errorf("split = %v after running narrow list", m.split)
+	}
+
+	// Every key the palette can send comes back as that key.
+	km := defaultKeyMap()
+	for name, b := range km.actions() {
+		for _, k := range b.Keys() {
+			if got := palette.KeyMsg(k).String(); got != k {
+				t.Errorf("%s: KeyMsg(%q) reads as %q", name, k, got)
+			}
+		}
+	}
+}
+
+func TestPickerPreviewMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	md := ansi.Strip(previewText(write("notes.md", "# Plans\n\nShip **soon**.\n"), 40))
+	if !strings.Contains(md, "Plans") || strings.Contains(md, "#") || strings.Contains(md, "**") {
+		t.Errorf("markdown not rendered:\n%s", md)
+	}
+	if txt := previewText(write("notes.txt", "# Plans **as is**\n"), 40); txt != "# Plans **as is**\n" {
+		t.Errorf("text preview = %q", txt)
+	}
+	if bin := previewText(write("blob.md", "PK\x00\x03"), 40); bin != "binary file" {
+		t.Errorf("binary preview = %q", bin)
+	}
+
+	// Markdown is rewrapped when the pane changes width.
+	write("long.md", strings.Repeat("word ", 40)+"\n")
+	var tm tea.Model = newModel(dir, options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	for filepath.Base(tm.(model).previewPath) != "long.md" {
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	narrow := tm.(model).preview.TotalLineCount()
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 160, Height: 24})
+	if wide := tm.(model).preview.TotalLineCount(); wide >= narrow {
+		t.Errorf("preview has %d lines at 160 columns and %d at 80", wide, narrow)
+	}
+}
+
+func TestStartDir(t *testing.T) {
+	dir := setupTree(t)
+	t.Setenv("HOME", dir)
+	t.Chdir(filepath.Join(dir, "sub"))
+
+	if got, err := startDir("", false); err != nil || got != dir {
+		t.Errorf("startDir() = %q, %v; want %q without Downloads", got, err, dir)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "Downloads"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := startDir("", false); got != filepath.Join(dir, "Downloads") {
+		t.Errorf("startDir() = %q, want ~/Downloads", got)
+	}
+	if got, err := startDir("", true); err != nil || got != filepath.Join(dir, "sub") {
+		t.Errorf("startDir(-cwd) = %q, %v; want the working directory", got, err)
+	}
+	if got, err := startDir("..", false); err != nil || got != dir {
+		t.Errorf("startDir(-dir ..) = %q, %v; want %q", got, err, dir)
+	}
+
+	for _, bad := range []string{"missing", "note.txt"} {
+		if _, err := startDir(bad, false); err == nil {
+			t.Errorf("startDir(-dir %s) should fail", bad)
+		}
+	}
+	if _, err := startDir(dir, true); err == nil {
+		t.Error("-dir with -cwd should fail")
+	}
+}
+
+func TestPickerBookmarks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := setupTree(t)
+	zeta := filepath.Join(dir, "zeta")
+	if err := os.Mkdir(zeta, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	titles := func(m model) []string {
+		var got []string
+		for _, li := range m.list.Items() {
+			got = append(got, ansi.Strip(li.(item).Title()))
+		}
+		return got
+	}
+
+	// Entries are "..", "a.md", "sub", "zeta": bookmark zeta, which moves
+	// up under "..".
+	var tm tea.Model = newModel(dir, options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	for range 3 {
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	tm, _ = tm.Update(runes("b"))
+	m := tm.(model)
+	if saved, err := loadBookmarks(); err != nil || !slices.Equal(saved, []string{zeta}) {
+		t.Fatalf("bookmarks file = %q, %v", saved, err)
+	}
+	if got := titles(m); len(got) != 4 || !strings.HasPrefix(got[1], bookmarkIcon) || !strings.Contains(got[1], "zeta") {
+		t.Errorf("titles = %q, want zeta starred after ..", got)
+	}
+	if i := m.list.SelectedItem().(item); i.path != zeta {
+		t.Errorf("highlight moved to %q", i.path)
+	}
+
+	// Only folders can be bookmarked.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(runes("b"))
+	if tm.(model).err == "" {
+		t.Error("a file was bookmarked")
+	}
+
+	// From inside sub, g lists the bookmarks and enter goes to zeta.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	tm, _ = tm.Update(runes("g"))
+	if tm.(model).bookmarks == nil {
+		t.Fatal("g didn't open the bookmarks")
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m := tm.(model); m.currentDir != zeta || m.bookmarks != nil {
+		t.Errorf("currentDir = %q, want %q with the menu closed", m.currentDir, zeta)
+	}
+
+	// The next run reads the file and pins zeta from the start.
+	saved, _ := loadBookmarks()
+	if got := titles(newModel(dir, options{bookmarks: saved})); !strings.Contains(got[1], "zeta") {
+		t.Errorf("titles = %q, want zeta pinned", got)
+	}
+}
+
+func TestPickerHidden(t *testing.T) {
+	dir := setupTree(t)
+	if err := os.Mkdir(filepath.Join(dir, ".cache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".env", filepath.Join(".cache", "blob")} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func(m model) []string {
+		var got []string
+		for _, li := range m.list.Items() {
+			if i := li.(item); i.title != ".." {
+				got = append(got, filepath.ToSlash(ansi.Strip(i.title)))
+			} else {
+				got = append(got, "..")
+			}
+		}
+		return got
+	}
+
+	var tm tea.Model = newModel(dir, options{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	if got, want := names(tm.(model)), []string{"..", plain.Icon("📄 ", "") + "a.md", plain.Icon("📁 ", "") + "sub"}; !slices.Equal(got, want) {
+		t.Errorf("listed %q, want %q", got, want)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	m := tm.(model)
+	if got := names(m); len(got) != 5 || !strings.HasSuffix(got[1], ".cache") || !strings.HasSuffix(got[2], ".env") {
+		t.Errorf("with hidden shown listed %q", got)
+	}
+	if !strings.HasSuffix(m.list.Title, "[hidden shown]") {
+		t.Errorf("title = %q", m.list.Title)
+	}
+	if i := m.list.SelectedItem().(item); filepath.Base(i.path) != "a.md" {
+		t.Errorf("highlight moved to %q", i.path)
+	}
+
+	// Recursive listings leave out what is inside hidden folders too.
+	m = newModel(dir, options{recursive: true, maxDepth: defaultMaxDepth})
+	m.walk.cancel()
+	for _, li := range m.listing(dir, m.walkItems(dir)) {
+		if i := li.(item); i.title != ".." && strings.Contains(i.path, ".cache") {
+			t.Errorf("recursive listing has %q", i.path)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gitStatusStyles colours the -git status codes.
+var gitStatusStyles = map[string]lipgloss.Style{
+	"M":  lipgloss.NewStyle().Foreground(lipgloss.Color("#E5C07B")),
+	"A":  lipgloss.NewStyle().Foreground(lipgloss.Color("#73F59F")),
+	"D":  lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")),
+	"R":  lipgloss.NewStyle().Foreground(lipgloss.Color("#61AFEF")),
+	"??": lipgloss.NewStyle().Foreground(lipgloss.Color("#7F848E")),
+}
+
+// gitStatus runs git status once for the working tree containing dir and
+// maps the absolute path of each changed file to its status code: M, A,
+// D, R or ?? for untracked. It returns nil outside a repository.
+func gitStatus(dir string) map[string]string {
+	top, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil
+	}
+	root := realPath(strings.TrimSpace(string(top)))
+
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain=v1", "-z", "--", ".").Output()
+	if err != nil {
+		slog.Warn("git status failed", "dir", dir, "err", err)
+		return nil
+	}
+
+	status := make(map[string]string)
+	entries := bytes.Split(out, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		e := string(entries[i])
+		if len(e) < 4 {
+			continue
+		}
+		xy, path := e[:2], e[3:]
+		code := strings.TrimSpace(xy)
+		switch {
+		case xy == "??":
+		case strings.ContainsRune(xy, 'R'):
+			// Renames are followed by the old path, which isn't listed.
+			code = "R"
+			i++
+		case strings.ContainsRune(xy, 'D'):
+			code = "D"
+		case strings.ContainsRune(xy, 'A'):
+			code = "A"
+		default:
+			code = "M"
+		}
+		status[filepath.Join(root, strings.TrimSuffix(path, "/"))] = code
+	}
+	slog.Debug("git status", "dir", dir, "root", root, "changed", len(status))
+	return status
+}
+
+// annotateGit sets the git status of each item listed from dir. A
+// directory holding changes is marked M unless git reports it whole, as
+// it does for an untracked directory.
+func annotateGit(items []list.Item, dir string) {
+	status := gitStatus(dir)
+	if status == nil {
+		return
+	}
+	// Only dir is resolved: an entry that is itself a symlink is tracked
+	// by git as a link, with a status of its own.
+	resolved := realPath(dir)
+	for idx, li := range items {
+		i, ok := li.(item)
+		if !ok || i.title == ".." {
+			continue
+		}
+		rel, err := filepath.Rel(dir, i.path)
+		if err != nil {
+			continue
+		}
+		abs := filepath.Join(resolved, rel)
+		code, ok := status[abs]
+		if !ok && i.isDir {
+			prefix := abs + string(filepath.Separator)
+			for path := range status {
+				if strings.HasPrefix(path, prefix) {
+					code = "M"
+					break
+				}
+			}
+		}
+		i.git = code
+		items[idx] = i
+	}
+}
+
+// realPath makes path absolute with symlinks resolved, as git reports
+// it, so a repository reached through a link still matches. A path that
+// can't be resolved is only made absolute.
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
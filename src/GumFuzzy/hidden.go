@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// isHidden reports whether path, below dir, is a dotfile or inside a
+// dot-directory.
+func isHidden(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") && part != "." && part != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// hiddenFiltered drops the hidden entries of a listing of dir unless they
+// are being shown. The ".." entry always stays.
+func (m model) hiddenFiltered(dir string, items []list.Item) []list.Item {
+	if m.showHidden {
+		return items
+	}
+	kept := items[:0]
+	for _, li := range items {
+		if it := li.(item); it.title == ".." || !isHidden(dir, it.path) {
+			kept = append(kept, li)
+		}
+	}
+	return kept
+}
+
+// toggleHidden shows or hides dotfiles, listing the directory again and
+// keeping the highlighted entry where it is still listed.
+func (m *model) toggleHidden() {
+	m.showHidden = !m.showHidden
+	m.list.Title = m.title()
+	var current string
+	if i, ok := m.list.SelectedItem().(item); ok {
+		current = i.path
+	}
+	if !m.changeDir(m.currentDir) {
+		return
+	}
+	for idx, li := range m.list.Items() {
+		if li.(item).path == current {
+			m.list.Select(idx)
+			break
+		}
+	}
+}
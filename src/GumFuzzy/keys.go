@@ -0,0 +1,95 @@
+package main
+
+import "github.com/charmbracelet/bubbles/key"
+
+// keyMap holds the picker's own actions. Ctrl+C always quits, and the
+// list's navigation and filter keys are left to the list.
+type keyMap struct {
+	Quit          key.Binding
+	Select        key.Binding
+	SelectWithDir key.Binding
+	ChooseDir     key.Binding
+	ContentSearch key.Binding
+	ExportTree    key.Binding
+	Narrower      key.Binding
+	Wider         key.Binding
+	Complete      key.Binding
+	Copy          key.Binding
+	Move          key.Binding
+	Paste         key.Binding
+	Undo          key.Binding
+	PreviewDown   key.Binding
+	PreviewUp     key.Binding
+	Mark          key.Binding
+	MarkRange     key.Binding
+	Palette       key.Binding
+	Bookmark      key.Binding
+	Bookmarks     key.Binding
+	ToggleHidden  key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:          key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		Select:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+		SelectWithDir: key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "pick, print dir too")),
+		ChooseDir:     key.NewBinding(key.WithKeys("."), key.WithHelp(".", "choose this folder")),
+		ContentSearch: key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "search contents")),
+		ExportTree:    key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "export tree")),
+		Narrower:      key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "narrow list")),
+		Wider:         key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "widen list")),
+		Complete:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "complete filter")),
+		Copy:          key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "mark to copy")),
+		Move:          key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "mark to move")),
+		Paste:         key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "paste here")),
+		// u also pages up in the list; the picker takes it first.
+		Undo:        key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo copy/move")),
+		PreviewDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "scroll preview down")),
+		PreviewUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "scroll preview up")),
+		// Terminals send shift+space as a plain space, so the range is on
+		// ctrl+space, which arrives as ctrl+@.
+		Mark:      key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark file")),
+		MarkRange: key.NewBinding(key.WithKeys("ctrl+@"), key.WithHelp("ctrl+space", "mark range")),
+		// Terminals send ctrl+shift+p as ctrl+p, so the palette is on alt+p.
+		Palette: key.NewBinding(key.WithKeys("alt+p", "f1"), key.WithHelp("alt+p", "commands")),
+		// b and g also page up and jump to the top in the list; the
+		// picker takes them first.
+		Bookmark:  key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "bookmark folder")),
+		Bookmarks: key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "bookmarks")),
+		// Some terminals send ctrl+h for backspace; it only toggles
+		// outside the filter.
+		ToggleHidden: key.NewBinding(key.WithKeys("ctrl+h"), key.WithHelp("ctrl+h", "show hidden")),
+	}
+}
+
+// actions names each binding as it appears in config.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":            &k.Quit,
+		"select":          &k.Select,
+		"select_with_dir": &k.SelectWithDir,
+		"choose_dir":      &k.ChooseDir,
+		"content_search":  &k.ContentSearch,
+		"export_tree":     &k.ExportTree,
+		"narrower":        &k.Narrower,
+		"wider":           &k.Wider,
+		"complete":        &k.Complete,
+		"copy":            &k.Copy,
+		"move":            &k.Move,
+		"paste":           &k.Paste,
+		"undo":            &k.Undo,
+		"preview_down":    &k.PreviewDown,
+		"preview_up":      &k.PreviewUp,
+		"mark":            &k.Mark,
+		"mark_range":      &k.MarkRange,
+		"palette":         &k.Palette,
+		"bookmark":        &k.Bookmark,
+		"bookmarks":       &k.Bookmarks,
+		"toggle_hidden":   &k.ToggleHidden,
+	}
+}
+
+// help lists the bindings for the list's full help view.
+func (k keyMap) help() []key.Binding {
+	return []key.Binding{k.SelectWithDir, k.ContentSearch, k.ExportTree, k.Narrower, k.Wider, k.Copy, k.Move, k.Paste, k.Undo, k.PreviewDown, k.PreviewUp, k.Bookmark, k.Bookmarks, k.ToggleHidden, k.Mark, k.MarkRange, k.Palette}
+}
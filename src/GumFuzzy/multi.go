@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// markIcon is put before the title of a marked file.
+var markIcon = "✓ "
+
+// toggleMark marks or unmarks the highlighted file and makes it the
+// anchor for a range.
+func (m *model) toggleMark() {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || i.isDir {
+		m.err = "Only files can be marked"
+		return
+	}
+	m.setMark(i.path, !m.selected[i.path])
+	m.anchor = i.path
+}
+
+// markRange gives every file between the anchor and the highlighted entry
+// the anchor's state, so a run of files is marked or unmarked at once.
+// Marks outside the range are left as they are.
+func (m *model) markRange() {
+	visible := m.list.VisibleItems()
+	from := slices.IndexFunc(visible, func(li list.Item) bool {
+		i, ok := li.(item)
+		return ok && i.path == m.anchor
+	})
+	if m.anchor == "" || from == -1 {
+		m.err = "Mark a file with " + m.keys.Mark.Help().Key + " first to start a range"
+		return
+	}
+	to := m.list.Index()
+	if from > to {
+		from, to = to, from
+	}
+
+	state := m.selected[m.anchor]
+	n := 0
+	for _, li := range visible[from : to+1] {
+		if i, ok := li.(item); ok && !i.isDir {
+			m.setMark(i.path, state)
+			n++
+		}
+	}
+	slog.Debug("marked range", "files", n, "marked", state)
+}
+
+// setMark records path as marked or not and updates its entry so the list
+// shows the change.
+func (m *model) setMark(path string, on bool) {
+	if on {
+		m.selected[path] = true
+	} else {
+		delete(m.selected, path)
+	}
+	for idx, li := range m.list.Items() {
+		if i, ok := li.(item); ok && i.path == path {
+			i.marked = on
+			m.list.SetItem(idx, i)
+			break
+		}
+	}
+}
+
+// markListing flags the entries of a fresh listing that were marked
+// before, so marks survive leaving a directory and coming back.
+func (m *model) markListing(items []list.Item) {
+	for idx, li := range items {
+		if i, ok := li.(item); ok && m.selected[i.path] {
+			i.marked = true
+			items[idx] = i
+		}
+	}
+}
+
+// markedPaths lists the marked files in a stable order for printing.
+func (m model) markedPaths() []string {
+	paths := make([]string, 0, len(m.selected))
+	for path := range m.selected {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
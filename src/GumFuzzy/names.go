@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// parseExts reads -ext: a comma-separated list of extensions, with or
+// without the dot, kept lowercased without it.
+func parseExts(s string) []string {
+	var exts []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimPrefix(strings.TrimSpace(e), "."); e != "" {
+			exts = append(exts, strings.ToLower(e))
+		}
+	}
+	return exts
+}
+
+// nameMatches reports whether a file called name passes -ext and -glob,
+// ignoring case. With both set it must pass both.
+func (o options) nameMatches(name string) bool {
+	name = strings.ToLower(name)
+	if len(o.exts) > 0 {
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		found := false
+		for _, e := range o.exts {
+			if ext == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if o.glob != "" {
+		if ok, _ := filepath.Match(strings.ToLower(o.glob), name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// nameFiltered drops the files -ext and -glob rule out, keeping every
+// directory so there is still a way through.
+func (o options) nameFiltered(items []list.Item) []list.Item {
+	if len(o.exts) == 0 && o.glob == "" {
+		return items
+	}
+	kept := items[:0]
+	for _, li := range items {
+		if it := li.(item); it.isDir || o.nameMatches(filepath.Base(it.path)) {
+			kept = append(kept, li)
+		}
+	}
+	return kept
+}
+
+// nameFilter describes -ext and -glob for the title, e.g. "md, txt" or
+// "*.json".
+func (o options) nameFilter() string {
+	var parts []string
+	if len(o.exts) > 0 {
+		parts = append(parts, strings.Join(o.exts, ", "))
+	}
+	if o.glob != "" {
+		parts = append(parts, o.glob)
+	}
+	return strings.Join(parts, " + ")
+}
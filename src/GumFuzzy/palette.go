@@ -0,0 +1,53 @@
+package main
+
+import (
+	"aign/render/palette"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteSkipped are the actions that don't apply to the picker as it
+// was started: tab only completes a filter, and choosing a folder needs
+// -dirs-only.
+func (m model) paletteSkipped() map[string]bool {
+	skip := map[string]bool{"complete": true, "palette": true}
+	if !m.opts.dirsOnly {
+		skip["choose_dir"] = true
+	}
+	return skip
+}
+
+// openPalette lists the picker's actions in the command palette.
+func (m *model) openPalette() {
+	h, v := docStyle.GetFrameSize()
+	m.palette = palette.New(m.keys.actions(), m.paletteSkipped(), palette.Options{
+		Width:      m.width - h,
+		Height:     m.height - v - footerHeight,
+		TitleStyle: titleStyle,
+	})
+}
+
+// updatePalette handles keys while the palette is open. Choosing an
+// action closes it and returns the action's binding for the caller to
+// run.
+func (m *model) updatePalette(msg tea.KeyMsg) (*key.Binding, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		m.quitting = true
+		return nil, tea.Quit
+	}
+	b, closed, cmd := m.palette.Update(msg)
+	if closed {
+		m.palette = nil
+	}
+	return b, cmd
+}
+
+// runAction runs b as if its first key had been pressed.
+func (m model) runAction(b key.Binding) (model, tea.Cmd) {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return m, nil
+	}
+	return m.update(palette.KeyMsg(keys[0]))
+}
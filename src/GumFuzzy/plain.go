@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// applyPlain switches the picker's own styles to plain text for -plain,
+// which also leaves the mouse alone and names the highlighted entry in
+// the footer. The list's are replaced by plainList as each list is made.
+func applyPlain() {
+	markIcon = "[x] "
+	bookmarkIcon = "[*] "
+
+	flat := lipgloss.NewStyle()
+	errorStyle, noticeStyle = flat, flat
+	// Keep the width the border and padding took, so the split is the same.
+	previewStyle = flat.PaddingLeft(previewStyle.GetHorizontalFrameSize())
+	for code := range gitStatusStyles {
+		gitStatusStyles[code] = flat
+	}
+}
+
+// plainList makes l plain: the selected entry is pointed at with ">"
+// rather than a coloured bar, and pages are numbered instead of dotted.
+func plainList(l *list.Model) {
+	flat := lipgloss.NewStyle()
+	pointer := flat.Border(lipgloss.Border{Left: ">"}, false, false, false, true).PaddingLeft(1)
+
+	d := list.NewDefaultDelegate()
+	d.Styles.NormalTitle = flat.PaddingLeft(2)
+	d.Styles.NormalDesc = flat.PaddingLeft(2)
+	d.Styles.SelectedTitle = pointer
+	d.Styles.SelectedDesc = pointer
+	d.Styles.DimmedTitle = flat.PaddingLeft(2)
+	d.Styles.DimmedDesc = flat.PaddingLeft(2)
+	d.Styles.FilterMatch = flat
+	l.SetDelegate(d)
+
+	s := list.DefaultStyles()
+	s.Title = flat
+	s.TitleBar = flat.PaddingBottom(1)
+	s.StatusBar = flat.PaddingBottom(1)
+	s.StatusEmpty, s.StatusBarActiveFilter, s.StatusBarFilterCount = flat, flat, flat
+	s.NoItems, s.HelpStyle = flat, flat.PaddingTop(1)
+	l.Styles = s
+	l.Paginator.Type = paginator.Arabic
+}
+
+// announcement names the highlighted entry and its place in the list for
+// the -plain footer: "Highlighted notes.md, file 2 of 5".
+func (m model) announcement() string {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return fmt.Sprintf("No entries in %s", m.currentDir)
+	}
+	kind := "file"
+	if i.isDir {
+		kind = "folder"
+	}
+	name := filepath.Base(i.path)
+	if i.title == ".." {
+		name, kind = "..", "parent folder"
+	}
+	s := fmt.Sprintf("Highlighted %s, %s %d of %d", name, kind, m.list.Index()+1, len(m.list.VisibleItems()))
+	if i.git != "" {
+		s += ", git status " + i.git
+	}
+	return s
+}
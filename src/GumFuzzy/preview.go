@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aign/render"
+	"aign/render/plain"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+const (
+	// previewLines is how much of the highlighted file the preview shows;
+	// past the first screenful it is scrolled into view.
+	previewLines = 1000
+	// previewMaxBytes caps how much is read to fill the preview.
+	previewMaxBytes = 64 << 10
+
+	// The list's share of the width moves by splitStep per < or >, and
+	// stays within [minSplit, maxSplit] so neither pane disappears.
+	defaultSplit = 0.5
+	splitStep    = 0.05
+	minSplit     = 0.2
+	maxSplit     = 0.8
+
+	// splitConfigKey is where the preferred split is saved in config.
+	splitConfigKey = "picker_split"
+
+	// previewZone marks the preview pane so the mouse wheel over it
+	// scrolls the preview instead of the list.
+	previewZone = "preview"
+)
+
+var previewStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.NormalBorder()).
+	BorderLeft(true).
+	BorderForeground(lipgloss.Color("#7D56F4")).
+	PaddingLeft(1)
+
+// shiftSplit moves the divider between the list and the preview by delta.
+func (m *model) shiftSplit(delta float64) {
+	m.split = min(max(m.split+delta, minSplit), maxSplit)
+	m.resize()
+}
+
+// scrollPreview handles the keys and wheel events that scroll the preview
+// while the list keeps the keyboard, reporting whether msg was one.
+func (m *model) scrollPreview(msg tea.Msg) bool {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering || m.contentInput.Focused() {
+			return false
+		}
+		switch {
+		case key.Matches(msg, m.keys.PreviewDown):
+			m.preview.HalfPageDown()
+		case key.Matches(msg, m.keys.PreviewUp):
+			m.preview.HalfPageUp()
+		default:
+			return false
+		}
+		return true
+	case tea.MouseMsg:
+		if !tea.MouseEvent(msg).IsWheel() || !zone.Get(previewZone).InBounds(msg) {
+			return false
+		}
+		m.preview, _ = m.preview.Update(msg)
+		return true
+	}
+	return false
+}
+
+// syncPreview loads the highlighted entry into the preview when the
+// selection has moved.
+func (m *model) syncPreview() {
+	path := ""
+	i, ok := m.list.SelectedItem().(item)
+	if ok {
+		path = i.path
+	}
+	// Markdown is wrapped to the pane, so it is redone when that changes.
+	if path == m.previewPath && m.preview.Width == m.previewWidth {
+		return
+	}
+	m.previewPath, m.previewWidth = path, m.preview.Width
+
+	switch {
+	case !ok:
+		m.preview.SetContent("")
+	case i.isDir:
+		m.preview.SetContent("directory")
+	case i.line:
+		m.preview.SetContent(linePreview(path, m.preview.Width))
+	default:
+		m.preview.SetContent(previewText(path, m.preview.Width))
+	}
+	m.preview.GotoTop()
+}
+
+// previewText returns the first lines of the file at path, markdown
+// rendered to fit width, or a short note when it can't be shown as text.
+func previewText(path string, width int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return "cannot read file: " + err.Error()
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, previewMaxBytes))
+	if err != nil {
+		return "cannot read file: " + err.Error()
+	}
+	if isBinary(data) {
+		return "binary file"
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > previewLines {
+		lines = lines[:previewLines]
+	}
+	text := strings.ReplaceAll(strings.Join(lines, "\n"), "\t", "    ")
+	if isMarkdown(path) && width > 0 {
+		if out, err := renderMarkdown(text, width); err == nil {
+			return out
+		}
+	}
+	return text
+}
+
+// isMarkdown reports whether path names a markdown file.
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// renderMarkdown renders text through glamour as the editor does, without
+// colour under -plain.
+func renderMarkdown(text string, width int) (string, error) {
+	style := "dark"
+	if plain.Enabled() {
+		style = "notty"
+	}
+	return render.Markdown(text, render.Options{Style: style, Width: width})
+}
+
+// isBinary guesses whether data is binary from a NUL byte near the start,
+// the same heuristic git and grep use.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
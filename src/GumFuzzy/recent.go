@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"aign/render/config"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+const (
+	// recentConfigKey is where selection times are kept in config, as Unix
+	// seconds by path.
+	recentConfigKey = "picker_recent"
+	// recentLimit caps how many paths the access log remembers.
+	recentLimit = 200
+)
+
+// loadRecent reads the access log; a missing or unreadable log is empty.
+func loadRecent() map[string]int64 {
+	recent := make(map[string]int64)
+	config.Value(recentConfigKey, &recent)
+	return recent
+}
+
+// recordRecent notes path as selected now, dropping the oldest entries
+// beyond recentLimit.
+func recordRecent(path string) error {
+	recent := loadRecent()
+	recent[path] = time.Now().Unix()
+
+	if len(recent) > recentLimit {
+		paths := make([]string, 0, len(recent))
+		for p := range recent {
+			paths = append(paths, p)
+		}
+		sort.Slice(paths, func(i, j int) bool { return recent[paths[i]] > recent[paths[j]] })
+		for _, p := range paths[recentLimit:] {
+			delete(recent, p)
+		}
+	}
+	return config.SetValue(recentConfigKey, recent)
+}
+
+// sortRecent moves files that have been selected before to the front of
+// items, most recent first, after any ".." entry. Everything else keeps its
+// order.
+func sortRecent(items []list.Item, recent map[string]int64) {
+	start := 0
+	if len(items) > 0 && items[0].(item).title == ".." {
+		start = 1
+	}
+	rank := func(li list.Item) int64 {
+		if i := li.(item); !i.isDir {
+			return recent[i.path]
+		}
+		return 0
+	}
+	rest := items[start:]
+	sort.SliceStable(rest, func(i, j int) bool { return rank(rest[i]) > rank(rest[j]) })
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// sizeUnits are the suffixes -min-size and -max-size accept, in powers of
+// 1024.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"T", 1 << 40},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"", 1},
+}
+
+// sizeFlag is a file size given with an optional unit, as in 10M or 1.5G.
+// Zero means no limit.
+type sizeFlag int64
+
+func (s *sizeFlag) String() string {
+	if s == nil || *s == 0 {
+		return ""
+	}
+	return formatSize(int64(*s))
+}
+
+func (s *sizeFlag) Set(v string) error {
+	n, err := parseSize(v)
+	if err != nil {
+		return err
+	}
+	*s = sizeFlag(n)
+	return nil
+}
+
+// parseSize reads a size such as 512, 10K, 10M, 1.5G or 2GiB.
+func parseSize(s string) (int64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	t = strings.TrimSuffix(strings.TrimSuffix(t, "B"), "I")
+	for _, u := range sizeUnits {
+		num, ok := strings.CutSuffix(t, u.suffix)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if err != nil || f < 0 {
+			return 0, fmt.Errorf("size %q: want a number with an optional K, M, G or T", s)
+		}
+		return int64(f * float64(u.bytes)), nil
+	}
+	return 0, fmt.Errorf("size %q: want a number with an optional K, M, G or T", s)
+}
+
+// formatSize writes n in the largest unit that keeps it at least 1, as
+// parseSize reads it back.
+func formatSize(n int64) string {
+	for _, u := range sizeUnits {
+		if n >= u.bytes && u.bytes > 1 {
+			return strconv.FormatFloat(float64(n)/float64(u.bytes), 'f', -1, 64) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// humanSize gives n bytes for reading, with one decimal in the largest
+// unit that keeps it at least 1: "512 B", "2.4 MB".
+func humanSize(n int64) string {
+	for _, u := range sizeUnits {
+		if n >= u.bytes && u.bytes > 1 {
+			return fmt.Sprintf("%.1f %sB", float64(n)/float64(u.bytes), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// inSizeRange reports whether a file of n bytes passes -min-size and
+// -max-size.
+func (o options) inSizeRange(n int64) bool {
+	return n >= o.minSize && (o.maxSize == 0 || n <= o.maxSize)
+}
+
+// sizeFiltered drops the files outside the size range, keeping every
+// directory so there is still a way through.
+func (o options) sizeFiltered(items []list.Item) []list.Item {
+	if o.minSize == 0 && o.maxSize == 0 {
+		return items
+	}
+	kept := items[:0]
+	for _, li := range items {
+		if it := li.(item); it.isDir || o.inSizeRange(it.size) {
+			kept = append(kept, li)
+		}
+	}
+	return kept
+}
+
+// sizeRange describes the range for the title, e.g. "10M–1G" or "≥ 10M".
+func (o options) sizeRange() string {
+	switch {
+	case o.minSize > 0 && o.maxSize > 0:
+		return formatSize(o.minSize) + "–" + formatSize(o.maxSize)
+	case o.minSize > 0:
+		return "≥ " + formatSize(o.minSize)
+	case o.maxSize > 0:
+		return "≤ " + formatSize(o.maxSize)
+	}
+	return ""
+}
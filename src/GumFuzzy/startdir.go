@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// startDir picks the directory the picker opens in: -dir when given, the
+// working directory under -cwd, otherwise ~/Downloads or, without one,
+// the home directory. A -dir that isn't a directory is an error rather
+// than a quiet fallback, so scripts notice the typo.
+func startDir(dir string, cwd bool) (string, error) {
+	switch {
+	case dir != "" && cwd:
+		return "", errors.New("-dir and -cwd can't be used together")
+	case dir != "":
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("-dir %q: %w", dir, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return "", fmt.Errorf("-dir %q: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("-dir %q: not a directory", dir)
+		}
+		return abs, nil
+	case cwd:
+		return os.Getwd()
+	}
+
+	home, _ := os.UserHomeDir()
+	downloads := filepath.Join(home, "Downloads")
+	if _, err := os.Stat(downloads); err != nil {
+		return home, nil
+	}
+	return downloads, nil
+}
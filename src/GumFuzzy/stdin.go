@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// stdinPiped reports whether stdin is a pipe or file rather than the
+// terminal, so -stdin has lines to read. A closed stdin isn't piped.
+func stdinPiped() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice == 0
+}
+
+// readLines reads the entries for -stdin, one per line, skipping blank
+// lines.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if line := strings.TrimRight(sc.Text(), "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// lineItems makes list entries of lines read from stdin. They may be paths
+// or any other text, so there is no parent entry and nothing to descend
+// into.
+func lineItems(lines []string) []list.Item {
+	items := make([]list.Item, len(lines))
+	for i, line := range lines {
+		items[i] = item{title: line, path: line, line: true}
+	}
+	return items
+}
+
+// linePreview shows the file a line names, or else the line itself.
+func linePreview(line string, width int) string {
+	if info, err := os.Stat(line); err == nil && info.Mode().IsRegular() {
+		return previewText(line, width)
+	}
+	return line
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// markdownTree renders the listing of root as nested bullets, one level per
+// directory, under a heading naming root. It lists exactly what the picker
+// would: one level normally, everything below root in recursive mode.
+func (m *model) markdownTree(root string) (string, error) {
+	items, err := m.getItems(root)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", filepath.Base(root))
+	for _, li := range items {
+		i, ok := li.(item)
+		if !ok || i.path == filepath.Dir(root) {
+			continue
+		}
+		rel, err := filepath.Rel(root, i.path)
+		if err != nil {
+			continue
+		}
+		depth := strings.Count(rel, string(filepath.Separator))
+		name := filepath.Base(rel)
+		if i.isDir {
+			name += "/"
+		}
+		fmt.Fprintf(&b, "%s- %s\n", strings.Repeat("  ", depth), name)
+	}
+	return b.String(), nil
+}
+
+// exportTree writes the current directory's tree to the -tree-out file, or
+// with no file set, keeps it to print on stdout and ends the picker.
+func (m *model) exportTree() bool {
+	tree, err := m.markdownTree(m.currentDir)
+	if err != nil {
+		m.err = dirError(m.currentDir, err)
+		return false
+	}
+	if m.opts.treeOut == "" {
+		m.tree = tree
+		return true
+	}
+	if err := os.WriteFile(m.opts.treeOut, []byte(tree), 0o644); err != nil {
+		slog.Error("write tree", "path", m.opts.treeOut, "err", err)
+		m.err = fmt.Sprintf("Cannot write tree: %v", err)
+		return false
+	}
+	slog.Info("wrote tree", "dir", m.currentDir, "path", m.opts.treeOut)
+	m.notice = "Tree written to " + m.opts.treeOut
+	return false
+}
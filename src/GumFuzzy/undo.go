@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// undoLimit is how many file operations u can step back through.
+const undoLimit = 20
+
+// fileOp is a paste the picker made, as kept for undo.
+type fileOp struct {
+	move     bool
+	src, dst string
+	replaced bool        // dst was overwritten, so what was there is gone
+	info     os.FileInfo // dst as the paste left it
+}
+
+func (op fileOp) verb() string {
+	if op.move {
+		return "move"
+	}
+	return "copy"
+}
+
+// logOp records op for undo, forgetting the oldest past undoLimit. An op
+// that overwrote dst also forgets the earlier ones that put a file there,
+// since undoing them now would remove or move the wrong file.
+func (m *model) logOp(op fileOp) {
+	if op.replaced {
+		m.ops = slices.DeleteFunc(m.ops, func(prev fileOp) bool { return prev.dst == op.dst })
+	}
+	m.ops = append(m.ops, op)
+	if len(m.ops) > undoLimit {
+		m.ops = m.ops[len(m.ops)-undoLimit:]
+	}
+}
+
+// changed reports why dst is no longer the file op left there, if it
+// isn't: something else took its place, or it was edited since.
+func (op fileOp) changed() error {
+	info, err := os.Lstat(op.dst)
+	if err != nil {
+		return err
+	}
+	if op.info == nil || !os.SameFile(info, op.info) || info.Size() != op.info.Size() || !info.ModTime().Equal(op.info.ModTime()) {
+		return fmt.Errorf("%s has changed since", op.dst)
+	}
+	return nil
+}
+
+// undo reverses the last file operation: a copy is removed and a move is
+// moved back. One that overwrote a file can't be, since the file it
+// replaced no longer exists; it is dropped from the log with a message.
+// So is one whose file has changed since, which is left where it is.
+func (m *model) undo() {
+	if len(m.ops) == 0 {
+		m.err = "Nothing to undo"
+		return
+	}
+	op := m.ops[len(m.ops)-1]
+	m.ops = m.ops[:len(m.ops)-1]
+
+	if op.replaced {
+		m.err = fmt.Sprintf("Can't undo the %s to %s: it replaced the file that was there", op.verb(), op.dst)
+		return
+	}
+	var err error
+	restored := op.src
+	if op.move {
+		if _, statErr := os.Lstat(op.src); statErr == nil {
+			err = fmt.Errorf("%s exists again", op.src)
+		} else if !errors.Is(statErr, os.ErrNotExist) {
+			err = statErr
+		} else if err = op.changed(); err == nil {
+			err = moveFile(op.dst, op.src)
+		}
+	} else {
+		if err = op.changed(); err == nil {
+			err = os.Remove(op.dst)
+		}
+		restored = ""
+	}
+	if err != nil {
+		slog.Warn("undo failed", "op", op.verb(), "src", op.src, "dst", op.dst, "err", err)
+		m.err = fmt.Sprintf("Can't undo the %s of %s: %v", op.verb(), filepath.Base(op.src), err)
+		return
+	}
+	slog.Info("undone", "op", op.verb(), "src", op.src, "dst", op.dst)
+
+	m.list.ResetFilter()
+	m.changeDir(m.currentDir)
+	if restored != "" {
+		for idx, li := range m.list.Items() {
+			if li.(item).path == restored {
+				m.list.Select(idx)
+				break
+			}
+		}
+		m.notice = "Moved back to " + restored
+	} else {
+		m.notice = "Removed the copy " + op.dst
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressInterval is the least time between progress reports from a
+// background walk, so a fast walk doesn't flood the UI with redraws.
+const progressInterval = 100 * time.Millisecond
+
+// walkItems lists every entry below root; see walkTree. Skipped symlink
+// cycles are recorded in m.cycles.
+func (m *model) walkItems(root string) []list.Item {
+	items, cycles := walkTree(context.Background(), root, m.opts, nil)
+	m.cycles = append(m.cycles, cycles...)
+	return items
+}
+
+// walkTree lists every entry below root, titled by its path relative to
+// root so the filter can match on directory names too. Unreadable
+// subdirectories are skipped rather than failing the whole listing.
+//
+// filepath.WalkDir never follows symlinks, so symlinked directories are
+// skipped unless followSymlinks is set, in which case they are walked
+// explicitly. Directories are tracked by their resolved path so a link back
+// to an ancestor (or to a tree already listed) is only visited once; the
+// skipped links are returned as cycles. A real directory that a link has
+// already walked is listed but not entered again.
+//
+// Below opts.maxDepth directory levels, directories are listed but not
+// entered: at 0 only root's own entries are listed, and a negative depth
+// has no limit.
+//
+// Cancelling ctx stops the walk with what has been found so far. If
+// progress is set, it is called every progressInterval with the number of
+// directories scanned and files found.
+func walkTree(ctx context.Context, root string, opts options, progress func(dirs, files int)) ([]list.Item, []string) {
+	var items []list.Item
+	var cycles []string
+	visited := make(map[string]bool)
+	var dirs, files int
+	last := time.Now()
+
+	var walk func(dir, rel string)
+	walk = func(dir, rel string) {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return fs.SkipAll
+			}
+			if progress != nil && time.Since(last) >= progressInterval {
+				progress(dirs, files)
+				last = time.Now()
+			}
+
+			if err != nil {
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			name, _ := filepath.Rel(dir, path)
+			name = filepath.Join(rel, name)
+			deepest := opts.maxDepth >= 0 && strings.Count(name, string(filepath.Separator)) >= opts.maxDepth
+
+			if d.IsDir() {
+				dirs++
+				if opts.followSymlinks {
+					// A link walked earlier may already have listed this
+					// directory's contents under the link's name.
+					if real, err := filepath.EvalSymlinks(path); err == nil {
+						if visited[real] && path != dir {
+							items = append(items, newItem(path, name, d))
+							return fs.SkipDir
+						}
+						visited[real] = true
+					}
+				}
+				if path == dir {
+					return nil
+				}
+				if deepest {
+					items = append(items, newItem(path, name, d))
+					return fs.SkipDir
+				}
+			} else {
+				files++
+			}
+
+			if d.Type()&fs.ModeSymlink != 0 {
+				info, err := os.Stat(path)
+				if err != nil || !info.IsDir() {
+					items = append(items, newItem(path, name, d))
+					return nil
+				}
+				if !opts.followSymlinks {
+					return nil
+				}
+				real, err := filepath.EvalSymlinks(path)
+				if err != nil {
+					return nil
+				}
+				if visited[real] {
+					cycles = append(cycles, path)
+					return nil
+				}
+				items = append(items, newItem(path, name, fs.FileInfoToDirEntry(info)))
+				if deepest {
+					return nil
+				}
+				// The trailing separator makes WalkDir resolve the link
+				// instead of reporting it as a leaf.
+				walk(path+string(filepath.Separator), name)
+				return nil
+			}
+
+			items = append(items, newItem(path, name, d))
+			return nil
+		})
+	}
+
+	walk(root, "")
+	return items, cycles
+}
+
+// walkState is a recursive listing running in the background. Model copies
+// share it by pointer, and messages carry it so that those from a walk
+// that has since been replaced are ignored.
+type walkState struct {
+	dir      string
+	cancel   context.CancelFunc
+	progress chan tea.Msg
+	done     chan tea.Msg
+}
+
+// walkProgressMsg reports how far a background walk has got.
+type walkProgressMsg struct {
+	walk        *walkState
+	dirs, files int
+}
+
+// walkDoneMsg carries a background walk's entries. If the walk was
+// cancelled they are the ones found until then.
+type walkDoneMsg struct {
+	walk      *walkState
+	items     []list.Item
+	cycles    []string
+	cancelled bool
+}
+
+// startWalk lists dir recursively in the background, cancelling any walk
+// already under way. Both channels are buffered and progress reports are
+// dropped while one is still waiting, so the walker never blocks, even
+// after nobody is listening any more.
+func (m *model) startWalk(dir string) {
+	if m.walk != nil {
+		m.walk.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &walkState{
+		dir:      dir,
+		cancel:   cancel,
+		progress: make(chan tea.Msg, 1),
+		done:     make(chan tea.Msg, 1),
+	}
+	m.walk = w
+
+	opts := m.opts
+	go func() {
+		items, cycles := walkTree(ctx, dir, opts, func(dirs, files int) {
+			select {
+			case w.progress <- walkProgressMsg{walk: w, dirs: dirs, files: files}:
+			default:
+			}
+		})
+		w.done <- walkDoneMsg{walk: w, items: items, cycles: cycles, cancelled: ctx.Err() != nil}
+	}()
+}
+
+// waitForWalk delivers the walk's next message.
+func waitForWalk(w *walkState) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-w.done:
+			return msg
+		case msg := <-w.progress:
+			return msg
+		}
+	}
+}
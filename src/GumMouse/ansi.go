@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// zoneMarker matches the escape sequences bubblezone wraps clickable
+// placeholders in. They mean nothing to a terminal outside the editor.
+var zoneMarker = regexp.MustCompile(`\x1b\[\d+z`)
+
+// ansiPath is where the rendered letter for the template at path is
+// written: letter.md becomes letter_filled.ansi.
+func ansiPath(path string) string {
+	return strings.TrimSuffix(path, ".md") + "_filled.ansi"
+}
+
+// exportANSI writes the letter as the editor shows it, colours and all, to
+// a file that looks the same when cat-ed.
+func (m *model) exportANSI() {
+	out := zoneMarker.ReplaceAllString(m.renderContent(), "")
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	path := ansiPath(m.filePath)
+	m.ansiErr = os.WriteFile(path, []byte(out+"\x1b[0m"), 0644)
+	if m.ansiErr != nil {
+		slog.Error("export ansi failed", "path", path, "err", m.ansiErr)
+		m.ansiSaved = ""
+		return
+	}
+	slog.Info("exported ansi", "path", path, "bytes", len(out))
+	m.ansiSaved = path
+}
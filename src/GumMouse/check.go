@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"aign/render"
+)
+
+// checkTemplate validates the template at path for -check: it reports how
+// many placeholders there are, delimiters that don't belong to any
+// placeholder, names written in more than one way, includes that can't be
+// resolved and whether glamour can render the letter. Included files are
+// only resolved here; check them on their own. Problems are written to w
+// as path:line:col lines; ok is false if there were any.
+func checkTemplate(w io.Writer, path, styleName string) (ok bool, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	text := blankLines(string(content), func(line string) bool {
+		_, include := includeTarget(line)
+		return include || isComment(line)
+	})
+	style := syntaxFor(styleName, text)
+	placeholders := ParsePlaceholders(text, style)
+
+	problems := 0
+	report := func(offset int, format string, args ...any) {
+		problems++
+		line, col := position(text, offset)
+		fmt.Fprintf(w, "%s:%d:%d: %s\n", path, line, col, fmt.Sprintf(format, args...))
+	}
+
+	if _, err := loadTemplate(path); err != nil {
+		problems++
+		fmt.Fprintln(w, err)
+	}
+
+	// A placeholder that runs over a line end or takes in another opening
+	// delimiter is one left unclosed. Whatever delimiters are left once the
+	// placeholders are blanked out are unclosed or empty ones.
+	masked := []byte(style.mask(text))
+	malformed := make(map[string]bool)
+	for _, loc := range style.pattern.FindAllStringIndex(string(masked), -1) {
+		match := text[loc[0]:loc[1]]
+		if strings.Contains(match, "\n") || strings.Contains(match[len(style.open):], style.open) {
+			malformed[match] = true
+			report(loc[0], "unclosed %q", style.open)
+		}
+		for i := loc[0]; i < loc[1]; i++ {
+			masked[i] = ' '
+		}
+	}
+	for _, delim := range stray(string(masked), style.open, style.close) {
+		// A > opening a line is a blockquote, not a closing angle.
+		lineStart := strings.LastIndex(text[:delim.offset], "\n") + 1
+		if delim.text == style.close && strings.TrimSpace(text[lineStart:delim.offset]) == "" {
+			continue
+		}
+		report(delim.offset, "stray %q without a matching placeholder", delim.text)
+	}
+
+	// The same name written two ways, e.g. [Company] and [company|Acme],
+	// is two fields where one was meant.
+	spellings := make(map[string][]Placeholder)
+	var keys []string
+	for _, ph := range placeholders {
+		if malformed[ph.Original] {
+			continue
+		}
+		key := normalizeField(ph.Label())
+		if len(spellings[key]) == 0 {
+			keys = append(keys, key)
+		}
+		spellings[key] = append(spellings[key], ph)
+	}
+	for _, key := range keys {
+		if phs := spellings[key]; len(phs) > 1 {
+			var forms []string
+			for _, ph := range phs {
+				forms = append(forms, ph.Original)
+			}
+			report(strings.Index(text, phs[1].Original), "%q is written %d ways: %s", phs[0].Label(), len(phs), strings.Join(forms, ", "))
+		}
+	}
+
+	// same= has to name a placeholder that is filled in for itself.
+	for _, ph := range placeholders {
+		if ph.Same == "" || malformed[ph.Original] {
+			continue
+		}
+		if src := spellings[normalizeField(ph.Same)]; len(src) == 0 || src[0].Same != "" {
+			report(strings.Index(text, ph.Original), "%q takes its value from %q, which isn't a placeholder of its own", ph.Label(), ph.Same)
+		}
+	}
+
+	if _, err := render.Markdown(text, render.Options{Width: 80}); err != nil {
+		problems++
+		fmt.Fprintf(w, "%s: glamour cannot render the letter: %v\n", path, err)
+	}
+
+	names := make(map[string]bool)
+	for _, ph := range placeholders {
+		if !malformed[ph.Original] {
+			names[ph.Label()] = true
+		}
+	}
+	summary := fmt.Sprintf("%s: %d placeholders (%s)", path, len(names), style.name)
+	if problems > 0 {
+		fmt.Fprintf(w, "%s, %d problems\n", summary, problems)
+		return false, nil
+	}
+	fmt.Fprintf(w, "%s, ok\n", summary)
+	return true, nil
+}
+
+// blankLines replaces the lines picked by blank with spaces so offsets
+// into the text still give the right line and column.
+func blankLines(text string, blank func(line string) bool) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if blank(line) {
+			body := strings.TrimSuffix(line, "\n")
+			lines[i] = strings.Repeat(" ", len(body)) + line[len(body):]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+type delimiter struct {
+	offset int
+	text   string
+}
+
+// stray finds every open and close delimiter in text, in order.
+func stray(text, open, close string) []delimiter {
+	var found []delimiter
+	for _, d := range []string{open, close} {
+		for i := 0; ; {
+			j := strings.Index(text[i:], d)
+			if j < 0 {
+				break
+			}
+			found = append(found, delimiter{i + j, d})
+			i += j + len(d)
+		}
+	}
+	sort.Slice(found, func(a, b int) bool { return found[a].offset < found[b].offset })
+	return found
+}
+
+// position turns a byte offset into a 1-based line and column.
+func position(text string, offset int) (line, col int) {
+	before := text[:offset]
+	line = strings.Count(before, "\n") + 1
+	col = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, col
+}
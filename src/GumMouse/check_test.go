@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckTemplate(t *testing.T) {
+	tests := []struct {
+		body   string
+		ok     bool
+		report []string
+	}{
+		{
+			"Dear [Company],\n\n%% [unclosed in a comment\n> quoted\nSee \\[1\\].\n",
+			true,
+			[]string{"1 placeholders (brackets), ok"},
+		},
+		{
+			"[] At [company] and [Company|Acme].\nDear [Company,\nThanks,\nfrom [Role].\n",
+			false,
+			[]string{
+				`:1:1: stray "["`,
+				`:1:2: stray "]"`,
+				`:1:21: "company" is written 2 ways: [company], [Company|Acme]`,
+				`:2:6: unclosed "["`,
+				"2 placeholders (brackets), 4 problems",
+			},
+		},
+		{
+			"At [Company], [Org:same=company] and [Team:same=Dept].\n",
+			false,
+			[]string{
+				`:1:38: "Team" takes its value from "Dept", which isn't a placeholder of its own`,
+				"3 placeholders (brackets), 1 problems",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		path := writeLetter(t, tt.body)
+		var out strings.Builder
+		ok, err := checkTemplate(&out, path, "brackets")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.ok {
+			t.Errorf("checkTemplate(%q) ok = %v, want %v", tt.body, ok, tt.ok)
+		}
+		for _, want := range tt.report {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("report for %q lacks %q:\n%s", tt.body, want, out.String())
+			}
+		}
+	}
+}
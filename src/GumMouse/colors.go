@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"aign/render/config"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// colorsConfigKey holds placeholder colours chosen over the theme's, by
+// style, e.g. {"colors": {"placeholder": {"fg": "#FF5F87", "bg": "#3C3C3C"}}}.
+const colorsConfigKey = "colors"
+
+// styleColors is one placeholder style's colours: "#rrggbb", "#rgb" or an
+// ANSI colour number, or empty to keep the theme's.
+type styleColors struct {
+	Foreground string `json:"fg,omitempty"`
+	Background string `json:"bg,omitempty"`
+}
+
+// colorTargets are the styles whose colours can be set, in the order the
+// colour editor lists them.
+var colorTargets = []struct {
+	key, label string
+	style      *lipgloss.Style
+}{
+	{"placeholder", "Empty placeholder", &placeholderStyle},
+	{"active", "Placeholder being edited", &activePlaceholderStyle},
+	{"filled", "Filled placeholder", &filledStyle},
+}
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+func checkColor(s string) error {
+	if s != "" && !colorPattern.MatchString(s) {
+		return fmt.Errorf("colour %q: want #rrggbb, #rgb or an ANSI number", s)
+	}
+	return nil
+}
+
+// currentColors reads the colours the placeholder styles have now.
+func currentColors() map[string]styleColors {
+	colors := make(map[string]styleColors, len(colorTargets))
+	for _, t := range colorTargets {
+		colors[t.key] = styleColors{
+			Foreground: colorString(t.style.GetForeground()),
+			Background: colorString(t.style.GetBackground()),
+		}
+	}
+	return colors
+}
+
+func colorString(c lipgloss.TerminalColor) string {
+	if c, ok := c.(lipgloss.Color); ok {
+		return string(c)
+	}
+	return ""
+}
+
+// applyColors sets the placeholder styles' colours on top of the theme.
+// Every colour is checked before any is applied.
+func applyColors(colors map[string]styleColors) error {
+	for name, c := range colors {
+		found := false
+		for _, t := range colorTargets {
+			found = found || t.key == name
+		}
+		if !found {
+			return fmt.Errorf("unknown style %q (want placeholder, active or filled)", name)
+		}
+		if err := checkColor(c.Foreground); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := checkColor(c.Background); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, t := range colorTargets {
+		c := colors[t.key]
+		if c.Foreground != "" {
+			*t.style = t.style.Foreground(lipgloss.Color(c.Foreground))
+		}
+		if c.Background != "" {
+			*t.style = t.style.Background(lipgloss.Color(c.Background))
+		}
+	}
+	return nil
+}
+
+// loadColors applies the colours saved in config, if any.
+func loadColors() error {
+	var colors map[string]styleColors
+	if !config.Value(colorsConfigKey, &colors) {
+		return nil
+	}
+	return applyColors(colors)
+}
+
+// colorEditor is the colour editor: a row for each style's foreground and
+// background, previewed in the letter as they change.
+type colorEditor struct {
+	row     int
+	editing bool
+	input   textinput.Model
+	before  map[string]styleColors // colours when the editor opened, for esc
+	err     error
+}
+
+// The colour editor's own keys.
+var (
+	colorUpKey   = key.NewBinding(key.WithKeys("up", "k"))
+	colorDownKey = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// colorRow names the style and which of its colours row edits.
+func colorRow(row int) (target int, background bool) {
+	return row / 2, row%2 == 1
+}
+
+// openColors starts the colour editor on the current colours.
+func (m *model) openColors() {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = 7
+	m.colors = &colorEditor{input: ti, before: currentColors()}
+}
+
+// setColor changes one colour, or with value empty removes it, and
+// re-renders the letter with it.
+func (m *model) setColor(row int, value string) error {
+	if err := checkColor(value); err != nil {
+		return err
+	}
+	target, background := colorRow(row)
+	style := colorTargets[target].style
+	switch {
+	case background && value == "":
+		*style = style.UnsetBackground()
+	case background:
+		*style = style.Background(lipgloss.Color(value))
+	case value == "":
+		*style = style.UnsetForeground()
+	default:
+		*style = style.Foreground(lipgloss.Color(value))
+	}
+	m.themeRev++
+	return nil
+}
+
+// updateColors handles keys while the colour editor is open.
+func (m *model) updateColors(msg tea.KeyMsg) tea.Cmd {
+	e := m.colors
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+
+	if e.editing {
+		switch {
+		case key.Matches(msg, m.keys.Commit):
+			if e.err = m.setColor(e.row, strings.TrimSpace(e.input.Value())); e.err == nil {
+				e.editing = false
+				e.input.Blur()
+			}
+		case key.Matches(msg, m.keys.Cancel):
+			e.editing, e.err = false, nil
+			e.input.Blur()
+		default:
+			var cmd tea.Cmd
+			e.input, cmd = e.input.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	rows := 2 * len(colorTargets)
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		// Put back what was there before the editor opened.
+		for _, t := range colorTargets {
+			*t.style = t.style.UnsetForeground().UnsetBackground()
+		}
+		applyColors(e.before)
+		m.themeRev++
+		m.colors = nil
+	case key.Matches(msg, m.keys.Save):
+		if e.err = config.SetValue(colorsConfigKey, currentColors()); e.err != nil {
+			slog.Error("save colours", "err", e.err)
+			return nil
+		}
+		slog.Info("saved colours", "colors", currentColors())
+		m.colors = nil
+	case key.Matches(msg, colorUpKey):
+		e.row = (e.row + rows - 1) % rows
+	case key.Matches(msg, colorDownKey):
+		e.row = (e.row + 1) % rows
+	case key.Matches(msg, m.keys.Commit):
+		target, background := colorRow(e.row)
+		c := currentColors()[colorTargets[target].key]
+		value := c.Foreground
+		if background {
+			value = c.Background
+		}
+		e.editing, e.err = true, nil
+		e.input.SetValue(value)
+		e.input.CursorEnd()
+		return e.input.Focus()
+	}
+	return nil
+}
+
+// colorsView lists each style's colours beside a sample. It sits above
+// the top of the letter, where the change shows in place.
+func (m model) colorsView() string {
+	e := m.colors
+	colors := currentColors()
+	rows := []string{helpStyle.Render("Placeholder colours"), ""}
+	for row := range 2 * len(colorTargets) {
+		target, background := colorRow(row)
+		t := colorTargets[target]
+		marker := "  "
+		if row == e.row {
+			marker = activePlaceholderStyle.Render(cursorMark) + " "
+		}
+		which, value := "foreground", colors[t.key].Foreground
+		if background {
+			which, value = "background", colors[t.key].Background
+		}
+		if row == e.row && e.editing {
+			value = e.input.View()
+		} else if value == "" {
+			value = helpStyle.Render("none")
+		}
+		label := t.label
+		if background {
+			label = ""
+		}
+		line := fmt.Sprintf("%s%-26s %-10s %s", marker, label, which, value)
+		if !background {
+			line += "  " + t.style.Render(" Company ")
+		}
+		rows = append(rows, line)
+	}
+	if e.err != nil {
+		rows = append(rows, "", errorStyle.Render(e.err.Error()))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// colorsHelp is the footer while the colour editor is open.
+func (m model) colorsHelp() string {
+	cancel := keyLabel(m.keys.Cancel.Help().Key)
+	if m.colors.editing {
+		return "enter = set • " + cancel + " = back"
+	}
+	return "↑↓ = move • enter = edit • " + keyLabel(m.keys.Save.Help().Key) + " = save to config • " + cancel + " = discard"
+}
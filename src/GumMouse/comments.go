@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// commentPrefix starts a template comment: a line of notes for whoever
+// fills the letter in, shown dimmed in the editor and left out of
+// everything the letter is saved or exported as.
+const commentPrefix = "%%"
+
+var commentStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240")).
+	Italic(true)
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), commentPrefix)
+}
+
+// stripComments drops the comment lines from text.
+func stripComments(text string) string {
+	if !strings.Contains(text, commentPrefix) {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !isComment(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+// markComments swaps each comment line in letter for a token and records
+// the dimmed text to put back in marks. With isolate, the token is set
+// apart as a paragraph of its own, so glamour neither restyles nor reflows
+// it into the next one. Tokens are numbered from first so they don't
+// collide with the placeholders'.
+func markComments(letter string, first, wrap int, isolate bool, marks map[string]string) string {
+	if !strings.Contains(letter, commentPrefix) {
+		return letter
+	}
+	lines := strings.Split(letter, "\n")
+	for i, line := range lines {
+		if !isComment(line) {
+			continue
+		}
+		styled := commentStyle.MaxWidth(wrap - 4).Render(strings.TrimSpace(line))
+		token := placeholderToken(first+i, cellWidth(styled))
+		marks[token] = styled
+		lines[i] = token
+		if isolate {
+			lines[i] = "\n" + token + "\n"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
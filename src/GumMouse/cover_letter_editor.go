@@ -1,17 +1,33 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
-	"regexp"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"aign/render"
+	"aign/render/config"
+	"aign/render/logging"
+	"aign/render/palette"
+	"aign/render/plain"
+	"aign/render/profiling"
+	"aign/render/terminal"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/client9/misspell"
 	zone "github.com/lrstanley/bubblezone"
+	"github.com/muesli/reflow/indent"
+	"github.com/muesli/reflow/wordwrap"
 )
 
 // Styles
@@ -37,6 +53,12 @@ var (
 				Background(lipgloss.Color("#F25D94")).
 				Bold(true)
 
+	// bracketStyle marks an empty placeholder's delimiters in the raw view.
+	bracketStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7D56F4")).
+			Background(lipgloss.Color("#3C3C3C")).
+			Bold(true)
+
 	filledStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#73F59F")).
 			Bold(true)
@@ -48,112 +70,545 @@ var (
 
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#626262"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F87")).
+			Bold(true)
+
+	timerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(lipgloss.Color("#3C3C3C")).
+			Padding(0, 1)
+
+	timerWarningStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Background(lipgloss.Color("#FF5F87")).
+				Bold(true).
+				Padding(0, 1)
+
+	wordLimitStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAF00")).
+			Bold(true)
+
+	sidebarStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), false, true, false, false).
+			BorderForeground(lipgloss.Color("#3C3C3C")).
+			Padding(0, 1)
 )
 
-// Placeholder represents a fillable field
+// sidebarWidth is the total width of the placeholder sidebar, border included.
+const sidebarWidth = 32
+
+// defaultCharLimit caps input for placeholders that declare no max=.
+const defaultCharLimit = 100
+
+// Placeholder represents a fillable field. A template may give it a
+// default after a pipe, e.g. [Deadline|+14d], and options after its
+// name: a length limit, a tab position or another placeholder it takes its
+// value from, e.g. [Summary:max=280], [Name:order=1] or
+// [Organization:same=Company]. A default of env:VAR is read from the
+// environment when the letter is loaded.
 type Placeholder struct {
 	ID       string
 	Original string
+	Name     string
 	Value    string
+	Default  string
+	MaxLen   int
+	Order    int
+	Same     string
+	Type     string // text, date, number or bool; see types.go
+	Linked   string // Same's current value, kept up to date by linkPlaceholders
+	typeNote string // the type as written, as in ":date"
 }
 
-type model struct {
-	width        int
-	height       int
-	letterText   string
-	filePath     string
-	placeholders []Placeholder
-	editing      int
-	textInput    textinput.Model
-	viewport     viewport.Model
-	ready        bool
-	saved        bool
-	glamourStyle string
-}
-
-func initialModel(letterPath string) model {
-	content, err := os.ReadFile(letterPath)
-	if err != nil {
-		content = []byte(defaultLetter)
+// Label is the placeholder's name without delimiters or default.
+func (ph Placeholder) Label() string {
+	return ph.Name
+}
+
+// Resolved is the text substituted for the placeholder: the typed value,
+// or else the value of the placeholder it is linked to, or else the
+// default with any relative date worked out against today.
+func (ph Placeholder) Resolved() string {
+	if ph.Value != "" {
+		return ph.Value
+	}
+	if ph.Linked != "" {
+		return ph.Linked
 	}
+	return resolveDefault(ph.Default, time.Now())
+}
+
+type model struct {
+	width           int
+	height          int
+	letterText      string
+	filePath        string
+	placeholders    []Placeholder
+	editing         int
+	cursor          int
+	textInput       textinput.Model
+	viewport        viewport.Model
+	ready           bool
+	saved           bool
+	glamourStyle    string
+	showSidebar     bool
+	deadline        time.Time
+	timeUp          bool
+	format          outputFormat
+	saveErr         error
+	savedPath       string
+	openAfterSave   bool
+	openErr         error
+	keys            keyMap
+	spellOpen       bool
+	spelling        []misspell.Diff
+	spellIndex      int
+	suggest         *suggestStream // suggestion streaming into the input
+	review          *reviewState
+	render          *renderCache
+	frozenStyles    *letterStyles // a background render's copy of the styles
+	inlineRows      int
+	raw             bool
+	syntax          placeholderSyntax
+	content         string
+	contentWidth    int
+	xOffset         int
+	reveal          bool // scroll to the edited placeholder once the render catches up
+	fromStdin       bool // the template was piped in, so filePath only names the output
+	loadErr         error
+	reloadErr       error
+	rescanWarn      string // set while a rescan or reload that drops values awaits confirmation
+	saves           int
+	suggestionsUsed int
+	output          string
+	final           *viewport.Model
+	showWraps       bool
+	signatureErr    error
+	renderErr       error  // why the letter is shown raw, from the last render
+	renderDismissed string // renderErr text hidden with esc
+	reflow          bool
+	posting         *postingState
+	ansiSaved       string // file the last ANSI export went to
+	ansiErr         error
+	pdfSaved        string // file the last PDF export went to
+	pdfErr          error
+	exportFormats   []outputFormat // what ctrl+x writes
+	exported        []string       // files the last ctrl+x wrote
+	exportErrs      []string       // formats it failed on, with why
+	colors          *colorEditor
+	models          *modelPicker
+	where           *whereState
+	diff            *diffState
+	summary         *summaryState
+	palette         *palette.Palette
+	saveSummary     bool   // show the summary screen after ctrl+s
+	history         bool   // record opens and saves for -recent
+	wordLimit       int    // words the footer count turns to a warning above
+	llmModel        string // name of the model chosen from llm_models, if any
+	truncated       int    // characters the input's limit cut from the last paste
+	typeErr         string // why the input doesn't fit its placeholder's type
+	announced       string // the edit that just started or ended, for -plain
+	floatInput      bool   // draw the editing input next to its placeholder
+	themeRev        int    // bumped when placeholder colours change, to re-render
+}
+
+// timerTickMsg drives the draft countdown once a second.
+type timerTickMsg time.Time
 
-	letterText := string(content)
+func timerTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return timerTickMsg(t)
+	})
+}
 
-	// Find all placeholders
-	re := regexp.MustCompile(`\[[^\]]+\]`)
-	matches := re.FindAllString(letterText, -1)
+// initialModel loads the letter at letterPath, finding placeholders in the
+// named style, or in whichever style the letter mostly uses for "auto".
+func initialModel(letterPath, styleName string) model {
+	// A letter that can't be read falls back to the sample; a broken
+	// include is reported instead, since the letter itself is there.
+	letterText, err := loadTemplate(letterPath)
+	var loadErr error
+	var incErr *includeError
+	if errors.As(err, &incErr) {
+		slog.Error("include failed", "path", letterPath, "err", err)
+		loadErr = err
+	} else if err != nil {
+		slog.Warn("using default letter", "path", letterPath, "err", err)
+		letterText = defaultLetter
+	}
 
-	seen := make(map[string]bool)
-	var placeholders []Placeholder
-	for i, match := range matches {
-		if !seen[match] {
-			seen[match] = true
-			placeholders = append(placeholders, Placeholder{
-				ID:       fmt.Sprintf("ph-%d", i),
-				Original: match,
-				Value:    "",
-			})
+	m := letterModel(letterText, letterPath, styleName)
+	m.loadErr = loadErr
+	if err == nil {
+		if n, err := loadProgress(m.placeholders, letterPath); err != nil {
+			slog.Warn("ignoring saved progress", "path", progressPath(letterPath), "err", err)
+		} else if n > 0 {
+			slog.Debug("restored progress", "path", letterPath, "filled", n)
 		}
 	}
+	return m
+}
+
+// letterModel is the editor for letterText, saved as if it had been read
+// from letterPath.
+func letterModel(letterText, letterPath, styleName string) model {
+	// Find all placeholders, leaving out any mentioned in comments
+	body := stripComments(letterText)
+	style := syntaxFor(styleName, body)
+	placeholders := ParsePlaceholders(body, style)
+	resolveEnvDefaults(placeholders)
+
+	slog.Debug("loaded letter", "path", letterPath, "style", style.name, "placeholders", len(placeholders))
 
 	ti := textinput.New()
 	ti.Placeholder = "Type replacement..."
-	ti.CharLimit = 100
+	ti.CharLimit = defaultCharLimit
 	ti.Width = 50
 
 	return model{
-		letterText:   letterText,
-		filePath:     letterPath,
-		placeholders: placeholders,
-		editing:      -1,
-		textInput:    ti,
-		glamourStyle: "dark",
+		letterText:    letterText,
+		filePath:      letterPath,
+		placeholders:  placeholders,
+		editing:       -1,
+		cursor:        -1,
+		textInput:     ti,
+		keys:          defaultKeyMap(),
+		glamourStyle:  "dark",
+		format:        formatMarkdown,
+		exportFormats: outputFormats,
+		wordLimit:     defaultWordLimit,
+		render:        &renderCache{},
+		syntax:        style,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if !m.deadline.IsZero() {
+		cmds = append(cmds, timerTick())
+	}
+	if m.editing != -1 {
+		cmds = append(cmds, textinput.Blink)
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm, ok := next.(model)
+	if !ok {
+		return next, cmd
+	}
+	linkPlaceholders(nm.placeholders, nm.editing, nm.textInput.Value())
+	// scheduleRender updates nm, so it has to run before nm is returned.
+	rc := nm.scheduleRender()
+	return nm, tea.Batch(cmd, rc)
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.spellOpen {
+			return m, m.updateSpelling(msg)
+		}
+		if m.review != nil {
+			return m, m.updateReview(msg)
+		}
+		if m.final != nil {
+			return m, m.updateFinal(msg)
+		}
+		if m.posting != nil {
+			return m, m.updatePosting(msg)
+		}
+		if m.colors != nil {
+			return m, m.updateColors(msg)
+		}
+		if m.models != nil {
+			return m, m.updateModels(msg)
+		}
+		if m.where != nil {
+			return m, m.updateWhere(msg)
+		}
+		if m.diff != nil {
+			return m, m.updateDiff(msg)
+		}
+		if m.summary != nil {
+			return m, m.updateSummary(msg)
+		}
+		if m.palette != nil {
+			b, cmd := m.updatePalette(msg)
+			if b == nil {
+				return m, cmd
+			}
+			return m.runAction(*b)
+		}
+		// While a suggestion streams into the input, esc stops it and
+		// other keys wait.
+		if m.suggest != nil {
+			switch {
+			case msg.String() == "ctrl+c":
+				return m, tea.Quit
+			case key.Matches(msg, m.keys.Cancel):
+				slog.Debug("suggestion stopped")
+				m.stopSuggest()
+			}
+			return m, nil
+		}
+		// A rescan or reload held back by reparse goes ahead if the very
+		// next key asks for it again.
+		confirm := m.rescanWarn != ""
+		m.rescanWarn = ""
+		m.ansiSaved, m.ansiErr = "", nil
+		m.pdfSaved, m.pdfErr = "", nil
+		m.exported, m.exportErrs = nil, nil
+		m.typeErr = ""
+		m.announced = ""
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			if m.editing == -1 {
 				return m, tea.Quit
 			}
-		case "esc":
+		case key.Matches(msg, m.keys.Cancel):
 			if m.editing != -1 {
+				m.announced = fmt.Sprintf("Stopped editing %s, %s", m.placeholders[m.editing].Label(), m.progress())
 				m.editing = -1
 				m.textInput.Blur()
+			} else if m.renderErrShown() {
+				m.renderDismissed = m.renderErr.Error()
 			}
-		case "enter":
+		case key.Matches(msg, m.keys.Commit):
 			if m.editing != -1 {
-				m.placeholders[m.editing].Value = m.textInput.Value()
-				m.editing = -1
-				m.textInput.Blur()
-				m.textInput.SetValue("")
-				m.saved = false
+				m.commitEdit()
 			}
-		case "ctrl+s":
-			m.saveToFile()
-			m.saved = true
-		case "tab":
+		case key.Matches(msg, m.keys.Save):
+			m.save()
+			if m.saved && m.saveSummary {
+				m.openSummary()
+			}
+			if m.saved && m.openAfterSave {
+				return m, openFile(m.savedPath)
+			}
+		case key.Matches(msg, m.keys.ExportAll):
 			if m.editing == -1 {
-				for i, ph := range m.placeholders {
-					if ph.Value == "" {
-						m.editing = i
-						m.textInput.SetValue("")
-						m.textInput.Placeholder = fmt.Sprintf("Enter %s", strings.Trim(ph.Original, "[]"))
-						m.textInput.Focus()
-						return m, textinput.Blink
-					}
+				m.exportAll()
+			}
+		case key.Matches(msg, m.keys.Format):
+			m.format = m.format.next()
+			slog.Debug("format changed", "format", m.format)
+			m.saved = false
+		case key.Matches(msg, m.keys.Next):
+			// Step through every placeholder in tab order, keeping what
+			// was typed into the one being left.
+			if len(m.placeholders) > 0 {
+				if m.editing != -1 && !m.commitEdit() {
+					return m, nil
+				}
+				return m, m.startEditing(m.tabStep(1))
+			}
+		case key.Matches(msg, m.keys.Prev):
+			if len(m.placeholders) > 0 {
+				if m.editing != -1 && !m.commitEdit() {
+					return m, nil
 				}
+				return m, m.startEditing(m.tabStep(-1))
 			}
+		case key.Matches(msg, m.keys.NextEmpty):
+			if m.editing != -1 && !m.commitEdit() {
+				return m, nil
+			}
+			for n := 1; n <= len(m.placeholders); n++ {
+				i := m.tabStep(n)
+				if m.placeholders[i].Resolved() == "" {
+					return m, m.startEditing(i)
+				}
+			}
+		case key.Matches(msg, m.keys.FirstEmpty):
+			if m.editing != -1 && !m.commitEdit() {
+				return m, nil
+			}
+			if i := m.firstEmpty(); i != -1 {
+				return m, m.startEditing(i)
+			}
+		case key.Matches(msg, m.keys.Sidebar):
+			m.showSidebar = !m.showSidebar
+			m.viewport.Width = m.viewportWidth()
+		case key.Matches(msg, m.keys.Raw):
+			if m.editing == -1 {
+				m.raw = !m.raw
+				slog.Debug("view changed", "raw", m.raw)
+				// Leaving the raw view picks up any placeholders the
+				// body gained.
+				if !m.raw {
+					m.rescan(false)
+				}
+			}
+		case key.Matches(msg, m.keys.Rescan):
+			if m.editing == -1 {
+				m.rescan(confirm)
+			}
+		case key.Matches(msg, m.keys.Reload):
+			if m.editing == -1 {
+				m.reload(confirm)
+			}
+		case key.Matches(msg, m.keys.Final):
+			if m.editing == -1 {
+				m.openFinal()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Diff):
+			if m.editing == -1 {
+				m.diff = &diffState{}
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Signature):
+			if m.editing == -1 {
+				return m, m.pickSignature()
+			}
+		case key.Matches(msg, m.keys.Where):
+			if m.editing == -1 {
+				m.openWhere()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Model):
+			if m.editing == -1 {
+				m.openModels()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Palette):
+			if m.editing == -1 {
+				m.openPalette()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Colors):
+			if m.editing == -1 {
+				m.openColors()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.ExportANSI):
+			if m.editing == -1 {
+				m.exportANSI()
+			}
+		case key.Matches(msg, m.keys.ExportPDF):
+			if m.editing == -1 {
+				m.exportPDF()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Posting):
+			if m.editing == -1 {
+				return m, m.openPosting()
+			}
+		case key.Matches(msg, m.keys.Suggest):
+			if m.editing != -1 && m.suggest == nil {
+				return m, m.suggestOne()
+			}
+		case key.Matches(msg, m.keys.SuggestAll):
+			if m.editing == -1 {
+				return m, m.startReview()
+			}
+		case key.Matches(msg, m.keys.Spelling):
+			if m.editing == -1 {
+				m.spelling = spellcheck(m.filledText(), m.placeholders)
+				slog.Debug("spellcheck", "flagged", len(m.spelling))
+				m.spellIndex = 0
+				m.spellOpen = true
+				return m, nil
+			}
+		}
+
+	case suggestChunkMsg:
+		if msg.stream != m.suggest {
+			return m, nil
+		}
+		m.textInput.SetValue(msg.value)
+		m.textInput.CursorEnd()
+		return m, waitForSuggest(msg.stream)
+
+	case suggestMsg:
+		if msg.stream != m.suggest {
+			return m, nil
+		}
+		m.suggest = nil
+		if m.editing == -1 || m.placeholders[m.editing].ID != msg.id {
+			return m, nil
+		}
+		if msg.err != nil {
+			slog.Warn("suggestion failed", "err", msg.err)
+			m.textInput.SetValue(msg.stream.before)
+			m.textInput.Placeholder = "No suggestion: " + msg.err.Error()
+			return m, nil
+		}
+		m.textInput.SetValue(msg.value)
+		m.textInput.CursorEnd()
+		m.suggestionsUsed++
+		return m, nil
+
+	case signatureMsg:
+		m.setSignature(msg)
+		return m, nil
+
+	case postingMsg:
+		m.setPosting(msg)
+		return m, nil
+
+	case reviewResultMsg:
+		if msg.review != m.review {
+			return m, nil
+		}
+		s := &m.review.items[msg.item]
+		s.value, s.err, s.done = msg.value, msg.err, true
+		m.checkSuggestion(s)
+		m.review.pending--
+		if m.review.pending > 0 {
+			return m, waitForReview(m.review)
+		}
+		return m, nil
+
+	case renderTickMsg:
+		if int(msg) != m.render.seq {
+			return m, nil
+		}
+		return m, m.renderLetter()
+
+	case renderedMsg:
+		m.render.key = msg.key
+		if msg.key == m.render.pending {
+			m.render.pending = ""
+		}
+		m.setRendered(msg.out, msg.err)
+		return m, nil
+
+	case openedMsg:
+		m.openErr = msg.err
+		if msg.err != nil {
+			slog.Error("open failed", "path", msg.path, "err", msg.err)
+		}
+		return m, nil
+
+	case list.FilterMatchesMsg:
+		// The lists filter in the background; the matches go back to
+		// whichever is open.
+		var cmd tea.Cmd
+		if m.palette != nil {
+			_, _, cmd = m.palette.Update(msg)
+		} else if m.models != nil {
+			m.models.list, cmd = m.models.list.Update(msg)
+		}
+		return m, cmd
+
+	case timerTickMsg:
+		if time.Time(msg).Before(m.deadline) {
+			return m, timerTick()
 		}
+		// Out of time: keep whatever has been written so far.
+		slog.Info("timer expired")
+		m.timeUp = true
+		m.save()
+		return m, nil
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -164,25 +619,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.editing != -1 {
 			footerHeight = 6
 		}
+		// Inline, the editor gets a fixed number of rows below the prompt
+		// and keeps room for the input box, since growing the footer would
+		// scroll the terminal.
+		if m.inlineRows > 0 {
+			m.height = min(msg.Height, m.inlineRows)
+			footerHeight = 6
+		}
 
 		if !m.ready {
-			m.viewport = viewport.New(msg.Width-4, msg.Height-headerHeight-footerHeight)
+			m.viewport = viewport.New(m.viewportWidth(), m.height-headerHeight-footerHeight)
 			m.viewport.YPosition = headerHeight
 			m.ready = true
 		} else {
-			m.viewport.Width = msg.Width - 4
-			m.viewport.Height = msg.Height - headerHeight - footerHeight
+			m.viewport.Width = m.viewportWidth()
+			m.viewport.Height = m.height - headerHeight - footerHeight
+		}
+		if m.models != nil {
+			m.models.list.SetSize(m.viewport.Width, m.viewport.Height)
+		}
+		if m.palette != nil {
+			m.palette.SetSize(m.viewport.Width, m.viewport.Height)
+		}
+		if m.final != nil {
+			return m, m.updateFinal(msg)
 		}
 
 	case tea.MouseMsg:
+		if m.final != nil {
+			return m, m.updateFinal(msg)
+		}
+		if n := wheelColumnsFor(msg); n != 0 {
+			m.scrollHorizontal(n)
+			return m, nil
+		}
 		if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+			if i := m.placeholderAt(msg.X, msg.Y); i != -1 {
+				return m, m.startEditing(i)
+			}
+			if i := m.taskAt(msg.X, msg.Y); i != -1 {
+				m.toggleTask(i)
+				return m, nil
+			}
 			for i, ph := range m.placeholders {
-				if zone.Get(ph.ID).InBounds(msg) {
-					m.editing = i
-					m.textInput.SetValue(ph.Value)
-					m.textInput.Placeholder = fmt.Sprintf("Enter %s", strings.Trim(ph.Original, "[]"))
-					m.textInput.Focus()
-					return m, textinput.Blink
+				if m.showSidebar && zone.Get(sidebarZoneID(ph)).InBounds(msg) {
+					return m, m.startEditing(i)
 				}
 			}
 		}
@@ -194,9 +675,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Update text input if editing
-	if m.editing != -1 {
+	if m.editing != -1 && !m.typedKey(m.placeholders[m.editing], msg) {
+		before := len([]rune(m.textInput.Value()))
 		var cmd tea.Cmd
 		m.textInput, cmd = m.textInput.Update(msg)
+		m.noteTruncation(msg, before)
 		cmds = append(cmds, cmd)
 	}
 
@@ -210,93 +693,533 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// startEditing focuses the input on placeholder i, seeded with its value.
+func (m *model) startEditing(i int) tea.Cmd {
+	m.stopSuggest()
+	ph := m.placeholders[i]
+	slog.Debug("edit placeholder", "placeholder", ph.Label())
+	m.editing = i
+	m.cursor = i
+	m.truncated = 0
+	m.typeErr = ""
+	m.textInput.CharLimit = defaultCharLimit
+	if ph.MaxLen > 0 {
+		m.textInput.CharLimit = ph.MaxLen
+	}
+	m.textInput.SetValue(ph.Value)
+	m.textInput.Placeholder = fmt.Sprintf("Enter %s", ph.Label())
+	if ph.Default != "" {
+		m.textInput.Placeholder += fmt.Sprintf(" (default: %s)", ph.Resolved())
+	}
+	m.textInput.Focus()
+	m.announced = fmt.Sprintf("Editing placeholder %s, %s", ph.Label(), m.progress())
+	m.revealPlaceholder(i)
+	m.reveal = true
+	return textinput.Blink
+}
+
+// firstEmpty is the index of the first placeholder in tab order with
+// nothing to substitute, or -1 when all are filled.
+func (m model) firstEmpty() int {
+	for _, i := range m.tabOrder() {
+		if m.placeholders[i].Resolved() == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// commitEdit stores the input as the value of the placeholder being edited
+// and closes the input. A value that doesn't fit the placeholder's type is
+// refused, leaving the input open, and commitEdit reports false.
+func (m *model) commitEdit() bool {
+	ph := m.placeholders[m.editing]
+	value, err := checkTyped(ph, m.textInput.Value())
+	if err != nil {
+		m.typeErr = err.Error()
+		return false
+	}
+	slog.Debug("fill placeholder", "placeholder", ph.Label(), "empty", value == "")
+	m.placeholders[m.editing].Value = value
+	verb := "Filled"
+	if value == "" {
+		verb = "Cleared"
+	}
+	m.announced = fmt.Sprintf("%s %s, %s", verb, ph.Label(), m.progress())
+	m.editing = -1
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.saved = false
+	m.saveProgress()
+	return true
+}
+
+func (m model) viewportWidth() int {
+	w := m.width - 4
+	if m.showSidebar {
+		w -= sidebarWidth
+	}
+	return w
+}
+
+func sidebarZoneID(ph Placeholder) string {
+	return "side-" + ph.ID
+}
+
+// sidebarView lists every placeholder with its fill state and value. Each
+// row is clickable and jumps to editing that placeholder.
+func (m model) sidebarView() string {
+	inner := sidebarWidth - sidebarStyle.GetHorizontalFrameSize()
+
+	rows := []string{helpStyle.Render("Fields")}
+	for i, ph := range m.placeholders {
+		value := ph.Resolved()
+		mark := placeholderStyle.Render(emptyMark)
+		if value != "" {
+			mark = filledStyle.Render(filledMark)
+		}
+		name := ph.Label()
+		if i == m.editing {
+			name = activePlaceholderStyle.Render(name)
+		}
+		row := lipgloss.NewStyle().MaxWidth(inner).Render(mark + " " + name)
+		if value != "" {
+			row += "\n" + helpStyle.MaxWidth(inner).Render("  "+value)
+		}
+		rows = append(rows, zone.Mark(sidebarZoneID(ph), row))
+	}
+
+	return sidebarStyle.
+		Width(inner).
+		Height(m.viewport.Height).
+		Render(strings.Join(rows, "\n"))
+}
+
 func (m model) renderContent() string {
-	// Build letter with clickable placeholders
-	letter := m.letterText
+	out, _ := m.renderContentErr()
+	return out
+}
 
-	for _, ph := range m.placeholders {
-		var replacement string
-		if ph.Value != "" {
-			replacement = zone.Mark(ph.ID, filledStyle.Render(ph.Value))
+// renderContentErr is renderContent, also returning why glamour failed
+// when the letter had to be shown as raw text instead.
+func (m model) renderContentErr() (string, error) {
+	// Glamour splits escape sequences apart when it styles text, which
+	// corrupts bubblezone markers. Render with plain tokens standing in for
+	// the placeholders, then swap the styled, clickable versions in.
+	letter := m.syntax.mask(m.letterText)
+	marks := make(map[string]string)
+
+	wrap := m.wrapWidth()
+
+	// Tokens for checkboxes and the words of long values are numbered
+	// after those for placeholders and comment lines.
+	next := len(m.placeholders) + strings.Count(letter, "\n") + 1
+	letter, next = m.markTasks(letter, next, marks)
+	styles := m.styles()
+	for i, ph := range m.placeholders {
+		var styled string
+		if value := ph.Resolved(); cellWidth(value) > longValueWidth {
+			var tokens []string
+			tokens, next = longValueTokens(ph.ID, markFilled(value), styles.filled, wrap, next, marks)
+			letter = strings.ReplaceAll(letter, ph.Original, strings.Join(tokens, " "))
+			continue
+		} else if value != "" {
+			styled = styles.filled.Render(markFilled(value))
 		} else if m.editing != -1 && m.placeholders[m.editing].ID == ph.ID {
-			replacement = zone.Mark(ph.ID, activePlaceholderStyle.Render(ph.Original))
+			styled = m.styleEmpty(ph, styles.active)
 		} else {
-			replacement = zone.Mark(ph.ID, placeholderStyle.Render(ph.Original))
+			styled = m.styleEmpty(ph, styles.empty)
 		}
-		letter = strings.Replace(letter, ph.Original, replacement, 1)
+		token := placeholderToken(i, cellWidth(styled))
+		marks[token] = zone.Mark(ph.ID, styled)
+		letter = strings.ReplaceAll(letter, ph.Original, token)
 	}
 
-	// Render with glamour for nice markdown
-	rendered, err := glamour.Render(letter, m.glamourStyle)
-	if err != nil {
-		return letter
+	// Escapes stay in for glamour, which treats \[ as a literal [, and for
+	// the raw view, which shows the template as written.
+	letter = m.syntax.unmask(letter, true)
+	letter = markComments(letter, len(m.placeholders), wrap, !m.raw, marks)
+	var rendered, unwrapped string
+	var renderErr error
+	if m.raw {
+		if m.showWraps {
+			// Leave a column for the marks.
+			rendered = indent.String(wordwrap.String(letter, wrap-1), 1)
+		} else {
+			rendered = wordwrap.String(letter, wrap)
+		}
+		unwrapped = letter
+	} else {
+		rendered, renderErr = render.Markdown(letter, render.Options{Style: m.glamourStyle, Width: wrap})
+		if renderErr != nil {
+			// Keep the letter editable as raw text.
+			rendered = wordwrap.String(letter, wrap)
+		}
+		if m.showWraps {
+			unwrapped, _ = render.Markdown(letter, render.Options{Style: m.glamourStyle})
+		}
+	}
+	if m.showWraps {
+		rendered = markWraps(rendered, unwrapped)
+	}
+
+	for token, mark := range marks {
+		rendered = strings.ReplaceAll(rendered, token, mark)
+	}
+	return rendered, renderErr
+}
+
+// styleEmpty draws an empty placeholder. The raw view picks out its
+// delimiters so its exact extent in the text is visible.
+func (m model) styleEmpty(ph Placeholder, style lipgloss.Style) string {
+	if !m.raw {
+		return style.Render(markEmpty(ph.Bare()))
+	}
+	open, close := m.syntax.open, m.syntax.close
+	inner := strings.TrimSuffix(strings.TrimPrefix(ph.Bare(), open), close)
+	return bracketStyle.Render(markEmpty(open)) + style.Render(inner) + bracketStyle.Render(close)
+}
+
+// wrapWidth is the width the letter is rendered at: glamour's usual 80
+// columns, narrowed when the viewport is smaller.
+func (m model) wrapWidth() int {
+	if m.viewport.Width > 0 {
+		return min(80, m.viewport.Width)
 	}
+	return 80
+}
+
+// placeholderToken builds a markdown-inert stand-in for placeholder i out of
+// private-use runes, padded to width so glamour wraps lines as it would
+// around the real text.
+func placeholderToken(i, width int) string {
+	const (
+		delim = '\uE000'
+		digit = '\uE010'
+		pad   = '\uE001'
+	)
 
-	return rendered
+	id := strconv.Itoa(i)
+	var b strings.Builder
+	b.WriteRune(delim)
+	for _, d := range id {
+		b.WriteRune(digit + d - '0')
+	}
+	for n := len(id) + 2; n < width; n++ {
+		b.WriteRune(pad)
+	}
+	b.WriteRune(delim)
+	return b.String()
 }
 
 func (m model) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
+	if m.final != nil {
+		return m.final.View()
+	}
 
 	var sb strings.Builder
 
-	// Header
-	title := titleStyle.Render("📝 Cover Letter Editor")
-	file := statusStyle.Render(m.filePath)
-	header := lipgloss.JoinHorizontal(lipgloss.Center, title, " ", file)
-	sb.WriteString(header)
-	sb.WriteString("\n\n")
-
-	// Update viewport content
-	m.viewport.SetContent(m.renderContent())
+	sb.WriteString(m.headerView())
+	sb.WriteString("\n")
+	if plain.Enabled() {
+		if m.announced != "" {
+			sb.WriteString(m.announced)
+		} else {
+			sb.WriteString(m.announcement())
+		}
+	}
+	sb.WriteString("\n")
 
-	// Viewport (scrollable content)
-	sb.WriteString(m.viewport.View())
+	// Viewport (scrollable content), with the field list beside it
+	body := m.viewport.View()
+	floating := false
+	if m.editing != -1 && m.floatInput && !plain.Enabled() {
+		body, floating = m.floatingInput(body)
+	}
+	if m.spellOpen {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.spellingView())
+	}
+	if m.review != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.reviewView())
+	}
+	if m.summary != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.summaryView())
+	}
+	if m.where != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.whereView())
+	}
+	if m.diff != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.diffView())
+	}
+	if m.models != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.modelsView())
+	}
+	if m.palette != nil {
+		body = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.palette.View())
+	}
+	if m.colors != nil {
+		panel := m.colorsView()
+		letter := strings.Split(body, "\n")
+		keep := max(m.viewport.Height-lipgloss.Height(panel)-1, 0)
+		body = panel + "\n\n" + strings.Join(letter[:min(keep, len(letter))], "\n")
+	}
+	if m.showSidebar {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, m.sidebarView(), body)
+	}
+	sb.WriteString(body)
 	sb.WriteString("\n")
 
 	// Footer
 	if m.editing != -1 {
-		sb.WriteString(inputBoxStyle.Render(
-			fmt.Sprintf("✏️  %s: %s",
-				m.placeholders[m.editing].Original,
-				m.textInput.View(),
-			),
-		))
+		if floating {
+			sb.WriteString("\n")
+		} else {
+			ph := m.placeholders[m.editing]
+			sb.WriteString(inputBoxStyle.Render(plain.Icon("✏️  ", "") + ph.Bare() + ": " + m.inputView()))
+			sb.WriteString("\n")
+		}
+		help := helpText(m.keys.Commit, m.keys.Next, m.keys.Prev, m.keys.Suggest, m.keys.Cancel)
+		if m.suggest != nil {
+			help = plain.Icon("🤖 ", "") + "Thinking… • " + keyLabel(m.keys.Cancel.Help().Key) + " = stop"
+		}
+		sb.WriteString(helpStyle.Render(help))
+	} else if m.posting != nil {
+		sb.WriteString(m.postingView())
+	} else if m.summary != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render(m.summaryHelp()))
+	} else if m.where != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render(m.whereHelp()))
+	} else if m.diff != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render(m.diffHelp()))
+	} else if m.models != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("↑↓ = move • / = filter • enter = use for suggestions • " + keyLabel(m.keys.Cancel.Help().Key) + " = back"))
+	} else if m.colors != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render(m.colorsHelp()))
+	} else if m.palette != nil {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("type to filter • ↑↓ = move • enter = run • esc = back"))
+	} else if m.review != nil {
 		sb.WriteString("\n")
-		sb.WriteString(helpStyle.Render("Enter = save • Esc = cancel"))
+		sb.WriteString(helpStyle.Render(m.reviewHelp()))
+	} else if m.spellOpen {
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("↑↓ = next/previous word • " + keyLabel(m.keys.Cancel.Help().Key) + " = back to letter"))
 	} else {
-		filled := 0
-		for _, ph := range m.placeholders {
-			if ph.Value != "" {
-				filled++
-			}
+		filled := m.filledCount()
+		status := fmt.Sprintf(plain.Icon("📊 ", "")+"%d/%d filled", filled, len(m.placeholders))
+		if markInline {
+			status = fmt.Sprintf("%s %d filled • %s %d empty", filledMark, filled, emptyMark, len(m.placeholders)-filled)
 		}
-
-		status := fmt.Sprintf("📊 %d/%d filled", filled, len(m.placeholders))
+		status += fmt.Sprintf(" • %s%s", plain.Icon("💾 ", "format "), m.format)
 		if m.saved {
-			status += " • ✅ Saved!"
+			status += " • " + plain.Icon("✅ ", "") + "Saved!"
+		}
+		if m.ansiSaved != "" {
+			status += " • " + plain.Icon("🎨 ", "") + "Wrote " + filepath.Base(m.ansiSaved)
+		}
+		if m.pdfSaved != "" {
+			status += " • " + plain.Icon("📄 ", "") + "Exported PDF"
+		}
+		exported, exportErr := m.exportSummary()
+		if exported != "" {
+			status += " • " + plain.Icon("📦 ", "") + exported
+		}
+		sb.WriteString(helpStyle.Render(status+" • ") + m.countView())
+		if m.saveErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+m.saveErr.Error()))
+		} else if m.openErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"open: "+m.openErr.Error()))
+		} else if exportErr != "" {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"export: "+exportErr))
+		} else if m.ansiErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"export: "+m.ansiErr.Error()))
+		} else if m.pdfErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"PDF: "+m.pdfErr.Error()))
+		} else if m.signatureErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"signature: "+m.signatureErr.Error()))
+		} else if m.reloadErr != nil {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"reload: "+m.reloadErr.Error()))
+		} else if m.rescanWarn != "" {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("⚠️ ", "Warning: ")+m.rescanWarn))
+		} else if m.renderErrShown() {
+			sb.WriteString(" " + errorStyle.Render(plain.Icon("❌ ", "Error: ")+"render: "+m.renderErr.Error()+
+				" (showing raw text; "+keyLabel(m.keys.Cancel.Help().Key)+" = dismiss)"))
 		}
-		sb.WriteString(helpStyle.Render(status))
 		sb.WriteString("\n")
-		sb.WriteString(helpStyle.Render("🖱️ Click placeholder • Tab = next • Ctrl+S = save • Q = quit • ↑↓ = scroll"))
+		click := "🖱️ Click placeholder • "
+		if m.inlineRows > 0 || plain.Enabled() {
+			click = ""
+		}
+		sb.WriteString(helpStyle.Render(click +
+			helpText(m.keys.Next, m.keys.NextEmpty, m.keys.FirstEmpty, m.keys.Sidebar, m.keys.Where, m.keys.Save, m.keys.Format, m.keys.Spelling, m.keys.SuggestAll, m.keys.Raw, m.keys.Rescan, m.keys.Reload, m.keys.Final, m.keys.Diff, m.keys.Signature, m.keys.Posting, m.keys.Model, m.keys.ExportANSI, m.keys.ExportPDF, m.keys.ExportAll, m.keys.Colors, m.keys.Palette, m.keys.Quit) +
+			" • ↑↓ = scroll"))
 	}
 
 	return zone.Scan(sb.String())
 }
 
-func (m *model) saveToFile() {
-	result := m.letterText
+// inputView is the editing input, with a count against the placeholder's
+// max= limit if it has one.
+func (m model) inputView() string {
+	input := m.textInput.View()
+	if m.placeholders[m.editing].Type == typeBool {
+		input = m.boolToggle()
+	}
+	if limit := m.placeholders[m.editing].MaxLen; limit > 0 {
+		n := len([]rune(m.textInput.Value()))
+		counter := helpStyle.Render(fmt.Sprintf("%d/%d", n, limit))
+		if n >= limit {
+			counter = errorStyle.Render(fmt.Sprintf("%d/%d", n, limit))
+		}
+		input += " " + counter
+	}
+	if note := m.truncationNote(); note != "" {
+		input += " " + note
+	}
+	if m.typeErr != "" {
+		input += " " + errorStyle.Render(m.typeErr)
+	}
+	return input
+}
+
+// headerView is the title bar: the file, the AI model if one was chosen
+// and the draft timer if one is running.
+func (m model) headerView() string {
+	title := titleStyle.Render(plain.Icon("📝 ", "") + "Cover Letter Editor")
+	file := statusStyle.Render(m.filePath)
+	if m.fromStdin {
+		file = statusStyle.Render("stdin")
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Center, title, " ", file)
+	if m.llmModel != "" {
+		header = lipgloss.JoinHorizontal(lipgloss.Center, header, " ", statusStyle.Render(plain.Icon("🤖 ", "model ")+m.llmModel))
+	}
+	if !m.deadline.IsZero() {
+		header = lipgloss.JoinHorizontal(lipgloss.Center, header, " ", m.timerView())
+	}
+	return header
+}
+
+// viewportTop is the screen row the letter starts on, below the header
+// and the blank line after it. Mouse input is off under -plain, so the
+// announcement it adds isn't counted.
+func (m model) viewportTop() int {
+	return lipgloss.Height(m.headerView()) + 1
+}
+
+// timerView shows the time left, flashing once a second during the final
+// minute.
+func (m model) timerView() string {
+	if m.timeUp {
+		return timerWarningStyle.Render(plain.Icon("⏱ ", "") + "Time's up")
+	}
+	left := time.Until(m.deadline).Round(time.Second)
+	text := fmt.Sprintf(plain.Icon("⏱ ", "Time left ")+"%02d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	if left < time.Minute && int(left.Seconds())%2 == 0 {
+		return timerWarningStyle.Render(text)
+	}
+	return timerStyle.Render(text)
+}
+
+// filledText is the letter with every filled placeholder substituted, its
+// comment lines removed and escaped delimiters made literal.
+func (m model) filledText() string {
+	result := m.syntax.mask(stripComments(m.letterText))
 	for _, ph := range m.placeholders {
-		if ph.Value != "" {
-			result = strings.ReplaceAll(result, ph.Original, ph.Value)
+		if value := ph.Resolved(); value != "" {
+			result = strings.ReplaceAll(result, ph.Original, value)
+		} else if ph.Type != "" {
+			result = strings.ReplaceAll(result, ph.Original, ph.Bare())
 		}
 	}
+	return m.syntax.unmask(result, false)
+}
 
-	// Save as _filled version
-	outPath := strings.TrimSuffix(m.filePath, ".md") + "_filled.md"
-	os.WriteFile(outPath, []byte(result), 0644)
+// save writes the letter and records the outcome for the footer.
+func (m *model) save() {
+	m.saveErr = m.saveToFile()
+	m.openErr = nil
+	m.saved = m.saveErr == nil
+	if m.saveErr != nil {
+		slog.Error("save failed", "path", m.filePath, "format", m.format, "err", m.saveErr)
+		return
+	}
+	m.saves++
+	m.noteHistory(true)
+	m.clearProgress()
+}
+
+func (m *model) saveToFile() error {
+	outPath, err := m.outputPath(m.format)
+	if err != nil {
+		return err
+	}
+	if err := m.writeLetter(outPath, m.format); err != nil {
+		return err
+	}
+	m.savedPath = outPath
+	return nil
+}
+
+// outputPath is where ctrl+s saves the letter in format f: the -output
+// pattern filled in, or next to the template.
+func (m model) outputPath(f outputFormat) (string, error) {
+	if m.output != "" {
+		return expandOutput(m.output, m.placeholders)
+	}
+	return filledPath(m.filePath, f), nil
+}
+
+// writeLetter converts the filled letter to format f and writes it to path.
+func (m model) writeLetter(path string, f outputFormat) error {
+	md := m.filledText()
+	if m.reflow {
+		md = reflowMarkdown(md)
+	}
+	data, err := convertLetter(md, f)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	slog.Info("saved", "path", path, "format", f, "bytes", len(data))
+	return nil
+}
+
+// filledPath is where the filled letter for the template at path is saved
+// in format f: letter.md becomes letter_filled.pdf.
+func filledPath(path string, f outputFormat) string {
+	return strings.TrimSuffix(path, ".md") + "_filled." + string(f)
 }
 
 const defaultLetter = `# Cover Letter
@@ -317,21 +1240,232 @@ Sincerely,
 `
 
 func main() {
-	zone.NewGlobal()
+	timer := flag.Duration("timer", 0, "Draft countdown, e.g. 10m; the letter is saved when it runs out")
+	formatFlag := flag.String("format", "md", "Output format for ctrl+s: md, txt, html, pdf or docx")
+	styleFlag := flag.String("placeholder-style", styleAuto, "Placeholder syntax: brackets, mustache, angle, dollar or auto")
+	noProfile := flag.Bool("no-profile", false, "Don't fill placeholders from $XDG_CONFIG_HOME/aign/profile.json")
+	serverMode := flag.Bool("server", false, "Serve line-delimited JSON requests on stdin instead of opening the editor (see server.go)")
+	openAfterSave := flag.Bool("open-after-save", false, "Open the saved file with the default application after each ctrl+s")
+	inline := flag.Bool("inline", false, "Run below the prompt in a fixed number of rows instead of full screen (no mouse)")
+	inlineRows := flag.Int("inline-rows", 20, "Rows used by -inline, at least 12")
+	check := flag.Bool("check", false, "Check the template for stray delimiters, names written two ways and render errors; exit 1 on problems")
+	noAutofocus := flag.Bool("no-autofocus", false, "Don't start out editing the first empty placeholder")
+	themeFlag := flag.String("theme", themeDefault, "Placeholder colours: default, or colorblind for a palette with ○/● state marks")
+	count := flag.Bool("count", false, "Print the number and names of the letter's placeholders, then exit")
+	recent := flag.Bool("recent", false, "Pick the letter to edit from those opened before, with how far each got")
+	schema := flag.Bool("schema", false, "Print a JSON schema of the letter's placeholders (name, type, default, limits) for building forms, then exit")
+	output := flag.String("output", "", "File to save to instead of <letter>_filled.<format>; {name} is replaced by that placeholder's value, e.g. \"{company}_{role}_letter.md\"")
+	plainFlag := flag.Bool("plain", false, "Screen reader mode: no colour, emoji, borders or mouse, with the editor's state spelled out under the header")
+	showWraps := flag.Bool("show-wraps", false, "Mark lines that were wrapped to fit the window with ↪")
+	reflow := flag.Bool("reflow", false, "Tidy the letter as it is saved: trim trailing spaces, collapse blank lines and end with one newline")
+	stats := flag.Bool("stats", false, "On exit, print time spent, fields filled, saves and AI suggestions used to stderr")
+	verbose := flag.Bool("verbose", false, "Write a debug log to $XDG_STATE_HOME/aign/aign.log")
+	floatInput := flag.Bool("float-input", false, "Draw the editing input over the letter next to the placeholder being edited, in the footer when it is off-screen")
+	pprofPrefix := flag.String(profiling.Flag, "", "Write CPU and heap profiles of the editing session to `prefix`.cpu and prefix.mem")
+	profiling.HideFlag()
+	flag.Parse()
+
+	closeLog, err := logging.Setup("editor", *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging disabled: %v\n", err)
+	}
+	defer closeLog()
+
+	format, err := parseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := applyTheme(*themeFlag); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := loadColors(); err != nil {
+		path, _ := config.Path()
+		fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+		os.Exit(2)
+	}
+	exportFormats, err := loadExportFormats()
+	if err != nil {
+		path, _ := config.Path()
+		fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+		os.Exit(2)
+	}
+	if *plainFlag {
+		plain.Enable()
+		applyPlain()
+	}
+	if _, ok := lookupSyntax(*styleFlag); !ok && *styleFlag != styleAuto {
+		fmt.Fprintf(os.Stderr, "unknown placeholder style %q (want brackets, mustache, angle, dollar or auto)\n", *styleFlag)
+		os.Exit(2)
+	}
+
+	if *inline && *inlineRows < 12 {
+		fmt.Fprintln(os.Stderr, "-inline-rows must be at least 12")
+		os.Exit(2)
+	}
 
 	filePath := "cover_letter.md"
-	if len(os.Args) > 1 {
-		filePath = os.Args[1]
+	if flag.NArg() > 0 {
+		filePath = flag.Arg(0)
 	}
 
-	p := tea.NewProgram(
-		initialModel(filePath),
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	if *recent {
+		chosen, err := pickRecent()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if chosen == "" {
+			return
+		}
+		filePath = chosen
+	}
+
+	if *count {
+		if err := printPlaceholderCount(os.Stdout, filePath, *styleFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *schema {
+		if err := printSchema(os.Stdout, filePath, *styleFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *check {
+		ok, err := checkTemplate(os.Stdout, filePath, *styleFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *serverMode {
+		s := &server{output: *output}
+		if !*noProfile {
+			if s.profile, err = loadProfile(); err != nil {
+				path, _ := profilePath()
+				fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+				os.Exit(2)
+			}
+		}
+		if err := s.serve(os.Stdin, os.Stdout); err != nil {
+			slog.Error("server failed", "err", err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	defer terminal.RestoreOnPanic(os.Stdout)
 
-	if _, err := p.Run(); err != nil {
+	zone.NewGlobal()
+
+	keys := defaultKeyMap()
+	if err := config.LoadKeys("editor", keys.actions()); err != nil {
+		path, _ := config.Path()
+		fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+		os.Exit(2)
+	}
+
+	// With no letter named, a template piped in is edited instead of
+	// cover_letter.md; it is still saved to cover_letter_filled.md.
+	var m model
+	if flag.NArg() == 0 && !*recent && stdinPiped() {
+		if m, err = stdinModel(os.Stdin, filePath, *styleFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		m = initialModel(filePath, *styleFlag)
+	}
+	if m.loadErr != nil {
+		fmt.Fprintln(os.Stderr, m.loadErr)
+		os.Exit(2)
+	}
+	if err := checkOutput(*output, m.placeholders); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	m.keys = keys
+	m.format = format
+	m.output = *output
+	m.showWraps = *showWraps
+	m.reflow = *reflow
+	m.floatInput = *floatInput
+	m.exportFormats = exportFormats
+	m.saveSummary = saveSummaryEnabled()
+	m.wordLimit = loadWordLimit()
+	if l, ok := activeModel(); ok {
+		m.llmModel = l.Name
+	}
+	if *plainFlag {
+		m.glamourStyle = plainGlamourStyle
+	}
+	m.openAfterSave = *openAfterSave
+	if *inline {
+		m.inlineRows = *inlineRows
+	}
+	if !*noProfile {
+		profile, err := loadProfile()
+		if err != nil {
+			path, _ := profilePath()
+			fmt.Fprintf(os.Stderr, "Error in %s: %v\n", path, err)
+			os.Exit(2)
+		}
+		n := applyProfile(m.placeholders, profile)
+		slog.Debug("applied profile", "fields", len(profile), "filled", n)
+	}
+	if i := m.firstEmpty(); i != -1 && !*noAutofocus {
+		m.startEditing(i)
+	}
+	if *timer > 0 {
+		m.deadline = time.Now().Add(*timer)
+	}
+	m.history = !m.fromStdin
+	m.noteHistory(false)
+
+	// Inline, mouse reports are relative to the screen rather than the
+	// editor, so placeholders are reached from the keyboard only, as they
+	// are under -plain.
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	switch {
+	case *inline:
+		opts = nil
+	case *plainFlag:
+		opts = []tea.ProgramOption{tea.WithAltScreen()}
+	}
+	p := tea.NewProgram(m, opts...)
+
+	// Profiling covers the event loop, where every edit re-renders the
+	// letter, but not the template loading above.
+	stopProfiling, err := profiling.Start(*pprofPrefix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	start := time.Now()
+	final, err := p.Run()
+	if perr := stopProfiling(); perr != nil {
+		slog.Error("write profile", "err", perr)
+		fmt.Fprintf(os.Stderr, "Warning: profile incomplete: %v\n", perr)
+	}
+	if err != nil {
+		slog.Error("program failed", "err", err)
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+	if fm, ok := final.(model); ok && *stats {
+		writeStats(os.Stderr, fm, time.Since(start))
+	}
 }
@@ -0,0 +1,1474 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"aign/render/config"
+	"aign/render/logging"
+	"aign/render/palette"
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/exp/teatest"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestMain(m *testing.M) {
+	zone.NewGlobal()
+	// Keep the editor's log lines out of the test output, as main does
+	// without -verbose.
+	logging.Setup("editor", false)
+	os.Exit(m.Run())
+}
+
+func writeLetter(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "letter.md")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// waitForZone blocks until bubblezone has recorded bounds for id, which
+// happens asynchronously after a View is scanned.
+func waitForZone(t *testing.T, id string) *zone.ZoneInfo {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if z := zone.Get(id); z != nil && !z.IsZero() {
+			return z
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("zone %q never rendered", id)
+	return nil
+}
+
+func TestEditorClickTypeSave(t *testing.T) {
+	path := writeLetter(t, "Dear [Company],\n\nRegards,\n[Your Name]\n")
+	m := initialModel(path, "brackets")
+
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("[Company]"))
+	}, teatest.WithDuration(3*time.Second))
+
+	z := waitForZone(t, m.placeholders[0].ID)
+	tm.Send(tea.MouseMsg{
+		X:      z.StartX,
+		Y:      z.StartY,
+		Button: tea.MouseButtonLeft,
+		Action: tea.MouseActionRelease,
+	})
+	tm.Type("Acme")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+	fm, ok := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if !ok {
+		t.Fatalf("final model has unexpected type")
+	}
+
+	if got := fm.placeholders[0].Value; got != "Acme" {
+		t.Errorf("placeholder value = %q, want %q", got, "Acme")
+	}
+	if fm.placeholders[1].Value != "" {
+		t.Errorf("untouched placeholder was filled with %q", fm.placeholders[1].Value)
+	}
+	if fm.editing != -1 {
+		t.Errorf("editing = %d, want -1", fm.editing)
+	}
+	if !fm.saved {
+		t.Error("expected saved to be set")
+	}
+
+	out, err := os.ReadFile(strings.TrimSuffix(path, ".md") + "_filled.md")
+	if err != nil {
+		t.Fatalf("reading saved letter: %v", err)
+	}
+	if want := "Dear Acme,\n\nRegards,\n[Your Name]\n"; string(out) != want {
+		t.Errorf("saved letter = %q, want %q", out, want)
+	}
+}
+
+func TestEditorNextEmptySkipsFilled(t *testing.T) {
+	path := writeLetter(t, "[A] [B]\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "done"
+
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlN})
+	tm.Type("x")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if got := fm.placeholders[1].Value; got != "x" {
+		t.Errorf("second placeholder = %q, want %q", got, "x")
+	}
+}
+
+func TestEditorTabCyclesAll(t *testing.T) {
+	path := writeLetter(t, "[A] [B]\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "done"
+
+	// Tab lands on the filled [A] first, moves on to [B] keeping [A]'s
+	// value, then wraps back round to [A].
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Type("x")
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Type("!")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if got := fm.placeholders[0].Value; got != "done!" {
+		t.Errorf("first placeholder = %q, want %q", got, "done!")
+	}
+	if got := fm.placeholders[1].Value; got != "x" {
+		t.Errorf("second placeholder = %q, want %q", got, "x")
+	}
+}
+
+func TestEditorTabOrder(t *testing.T) {
+	path := writeLetter(t, "[A] [B:order=2] [C] [D:order=1]\n")
+	m := initialModel(path, "brackets")
+
+	// Ordered placeholders come first, lowest order first, then the rest
+	// as they appear: D, B, A, C. Shift+tab walks back from D to C.
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	for _, s := range []string{"1", "2", "3", "4"} {
+		tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+		tm.Type(s)
+	}
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Send(tea.KeyMsg{Type: tea.KeyShiftTab})
+	tm.Type("!")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	var got []string
+	for _, ph := range fm.placeholders {
+		got = append(got, ph.Value)
+	}
+	if want := []string{"3", "2", "4!", "1"}; !slices.Equal(got, want) {
+		t.Errorf("values = %q, want %q", got, want)
+	}
+}
+
+func TestEditorLinkedPlaceholders(t *testing.T) {
+	m := initialModel(writeLetter(t, "[Company] then [Organization:same=Company], [Org:max=2:same=Company].\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Acme")})
+
+	// The linked copy follows the typing before it is committed.
+	m = tm.(model)
+	if got := m.placeholders[1].Resolved(); got != "Acme" {
+		t.Errorf("linked placeholder while typing = %q, want %q", got, "Acme")
+	}
+	// A linked copy keeps to its own length limit.
+	if got := m.placeholders[2].Resolved(); got != "Ac" {
+		t.Errorf("linked placeholder with max=2 = %q, want %q", got, "Ac")
+	}
+	if out := ansi.Strip(m.renderContent()); !strings.Contains(out, "then Acme, Ac.") {
+		t.Errorf("render doesn't show the linked value:\n%s", out)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if got, want := m.filledText(), "Acme then Acme, Ac.\n"; got != want {
+		t.Errorf("filledText() = %q, want %q", got, want)
+	}
+
+	// Its own value wins over the link.
+	m.placeholders[1].Value = "Acme Corp"
+	if got, want := m.filledText(), "Acme then Acme Corp, Ac.\n"; got != want {
+		t.Errorf("filledText() = %q, want %q", got, want)
+	}
+}
+
+func TestCommentsStripped(t *testing.T) {
+	m := initialModel(writeLetter(t, "%% Mention [Team] if known\nDear [Company],\n  %% keep it short\nThanks\n"), "brackets")
+
+	if len(m.placeholders) != 1 || m.placeholders[0].Label() != "Company" {
+		t.Fatalf("placeholders = %+v, want only Company", m.placeholders)
+	}
+	m.placeholders[0].Value = "Acme"
+	if got, want := m.filledText(), "Dear Acme,\nThanks\n"; got != want {
+		t.Errorf("filledText() = %q, want %q", got, want)
+	}
+
+	if out := m.renderContent(); !strings.Contains(out, "keep it short") {
+		t.Errorf("comment missing from the editor view:\n%s", out)
+	}
+}
+
+// BenchmarkTypingView measures one keystroke in a placeholder's input on a
+// multi-page letter, Update plus View, as the editor does it per key.
+func BenchmarkTypingView(b *testing.B) {
+	var sb strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&sb, "## Section %d\n\nAt [Company] I would bring *experience* in [Skill %d] and a **track record** of shipping. ", i, i)
+		sb.WriteString(strings.Repeat("This paragraph pads the letter out to several pages of prose. ", 6))
+		sb.WriteString("\n\n")
+	}
+	path := filepath.Join(b.TempDir(), "letter.md")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		b.Fatal(err)
+	}
+
+	var tm tea.Model = initialModel(path, "brackets")
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm.View()
+
+	b.ResetTimer()
+	for range b.N {
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+		tm.View()
+	}
+}
+
+func TestInlineHeight(t *testing.T) {
+	m := initialModel(writeLetter(t, strings.Repeat("Dear [Company],\n\n", 40)), "brackets")
+	m.inlineRows = 14
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 50})
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyTab}} {
+		tm, _ = tm.Update(k)
+		if lines := strings.Count(tm.View(), "\n") + 1; lines > 14 {
+			t.Errorf("after %s view is %d lines, want at most 14", k, lines)
+		}
+	}
+}
+
+func TestEditorRawViewClick(t *testing.T) {
+	path := writeLetter(t, "# Title\n\nDear [Company],\n")
+	m := initialModel(path, "brackets")
+
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlO})
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("# Title"))
+	}, teatest.WithDuration(3*time.Second))
+
+	// Unrendered, the placeholder starts right after "Dear ".
+	deadline := time.Now().Add(3 * time.Second)
+	z := waitForZone(t, m.placeholders[0].ID)
+	for z.StartX != len("Dear ") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		z = zone.Get(m.placeholders[0].ID)
+	}
+	tm.Send(tea.MouseMsg{X: z.StartX, Y: z.StartY, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+	tm.Type("Acme")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	if !fm.raw {
+		t.Error("expected the raw view")
+	}
+	if got := fm.placeholders[0].Value; got != "Acme" {
+		t.Errorf("placeholder value = %q, want %q", got, "Acme")
+	}
+}
+
+func TestHorizontalWheelScroll(t *testing.T) {
+	wide := strings.Repeat("x", 60) + "END"
+	m := initialModel(writeLetter(t, "Dear [Company],\n\n```\n"+wide+"\n```\n"), "brackets")
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	wheel := func(b tea.MouseButton, shift bool) {
+		tm, _ = tm.Update(tea.MouseMsg{Button: b, Shift: shift, Action: tea.MouseActionPress})
+	}
+
+	wheel(tea.MouseButtonWheelRight, false)
+	wheel(tea.MouseButtonWheelDown, true)
+	if got := tm.(model).xOffset; got != 2*wheelColumns {
+		t.Errorf("xOffset = %d, want %d", got, 2*wheelColumns)
+	}
+
+	for range 20 {
+		wheel(tea.MouseButtonWheelRight, false)
+	}
+	fm := tm.(model)
+	if fm.xOffset != fm.contentWidth-fm.viewport.Width {
+		t.Errorf("xOffset = %d, want it clamped to %d", fm.xOffset, fm.contentWidth-fm.viewport.Width)
+	}
+	if !strings.Contains(fm.viewport.View(), "END") {
+		t.Errorf("end of the wide line not in view:\n%s", fm.viewport.View())
+	}
+
+	for range 20 {
+		wheel(tea.MouseButtonWheelLeft, false)
+	}
+	if got := tm.(model).xOffset; got != 0 {
+		t.Errorf("xOffset = %d after scrolling back, want 0", got)
+	}
+}
+
+func TestShiftLeftKeepsZones(t *testing.T) {
+	line := "Dear " + zone.Mark("ph", "\x1b[1mCompany\x1b[0m") + " 日本"
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, line},
+		{7, zone.Mark("ph", "\x1b[1mmpany\x1b[0m") + " 日本"},
+		{20, zone.Mark("ph", "\x1b[1m\x1b[0m")},
+		{14, zone.Mark("ph", "\x1b[1m\x1b[0m") + " 本"},
+	}
+	for _, tt := range tests {
+		if got := shiftLeft(line, tt.n); got != tt.want {
+			t.Errorf("shiftLeft(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestEditorAutofocusAndFirstEmpty(t *testing.T) {
+	m := initialModel(writeLetter(t, "[A] [B] [C]\n"), "brackets")
+	m.startEditing(m.firstEmpty())
+
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 30))
+	tm.Type("a")
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Send(tea.KeyMsg{Type: tea.KeyTab})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlHome})
+	tm.Type("b")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	fm := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(model)
+	for i, want := range []string{"a", "b", ""} {
+		if got := fm.placeholders[i].Value; got != want {
+			t.Errorf("placeholder %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestColorblindTheme(t *testing.T) {
+	saved := []lipgloss.Style{placeholderStyle, activePlaceholderStyle, bracketStyle, filledStyle}
+	t.Cleanup(func() {
+		placeholderStyle, activePlaceholderStyle, bracketStyle, filledStyle = saved[0], saved[1], saved[2], saved[3]
+		filledMark, emptyMark, markInline = "✓", "✗", false
+	})
+	if err := applyTheme("colorblind"); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTheme("neon"); err == nil {
+		t.Error("unknown theme accepted")
+	}
+
+	m := initialModel(writeLetter(t, "Dear [Company] and [Team]\n"), "brackets")
+	m.placeholders[0].Value = "Acme"
+	out := m.renderContent()
+	for _, want := range []string{"● Acme", "○ [Team]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestEditorRescan(t *testing.T) {
+	path := writeLetter(t, "Dear [Company], re [Role] and [Team].\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "Acme"
+	m.placeholders[2].Value = "Platform"
+
+	// Rescanning reads the letter text, not the file.
+	m.letterText = "Dear [Company], from [Name] on [Team].\n"
+	if err := os.WriteFile(path, []byte("[Other]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = tm.(model)
+
+	want := map[string]string{"Company": "Acme", "Name": "", "Team": "Platform"}
+	if len(m.placeholders) != len(want) {
+		t.Fatalf("placeholders = %+v, want %v", m.placeholders, want)
+	}
+	for _, ph := range m.placeholders {
+		if v, ok := want[ph.Name]; !ok || ph.Value != v {
+			t.Errorf("%s = %q, want %q", ph.Name, ph.Value, v)
+		}
+	}
+	if got, want := m.filledText(), "Dear Acme, from [Name] on Platform.\n"; got != want {
+		t.Errorf("filledText() = %q, want %q", got, want)
+	}
+
+	// Leaving the raw view rescans too.
+	m.letterText += "[Closing]\n"
+	tm = m
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = tm.(model)
+	if len(m.placeholders) != 4 || m.placeholders[3].Name != "Closing" {
+		t.Errorf("leaving raw didn't rescan: %+v", m.placeholders)
+	}
+
+	// A reload of a missing file keeps the letter.
+	os.Remove(path)
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyF5})
+	m = tm.(model)
+	if m.reloadErr == nil || len(m.placeholders) != 4 {
+		t.Errorf("reload of a missing file: err = %v, placeholders = %d", m.reloadErr, len(m.placeholders))
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	m := initialModel(writeLetter(t, "Dear [Company], re [Role].\n"), "brackets")
+	m.placeholders[0].Value = "Acme"
+	m.save()
+	m.save()
+	m.suggestionsUsed = 1
+
+	var buf bytes.Buffer
+	writeStats(&buf, m, 95*time.Second+400*time.Millisecond)
+	for _, want := range []string{"1m35s", "Fields filled:    1/2", "Saves:            2", "AI suggestions:   1 used"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("stats missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestEditorFinalPreview(t *testing.T) {
+	m := initialModel(writeLetter(t, "# Letter\n\nDear [Company],\n\n[Closing]\n"), "brackets")
+	m.placeholders[0].Value = "Acme"
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	view := ansi.Strip(tm.View())
+	for _, unwanted := range []string{"Cover Letter Editor", "filled", "[Company]"} {
+		if strings.Contains(view, unwanted) {
+			t.Errorf("final preview shows %q:\n%s", unwanted, view)
+		}
+	}
+	if !strings.Contains(view, "Acme") || !strings.Contains(view, "[Closing]") {
+		t.Errorf("final preview should show the letter as saved:\n%s", view)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if tm.(model).final != nil || !strings.Contains(tm.View(), "Cover Letter Editor") {
+		t.Error("esc should return to the editor")
+	}
+}
+
+func TestShowWraps(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	letter := "Dear [Company],\nI saw the role.\n\n" + long + "end.\n\nSincerely,\n[Your Name]\n"
+	for _, raw := range []bool{false, true} {
+		m := initialModel(writeLetter(t, letter), "brackets")
+		m.raw, m.showWraps = raw, true
+		var tm tea.Model = m
+		tm, _ = tm.Update(tea.WindowSizeMsg{Width: 50, Height: 30})
+		m = tm.(model)
+
+		out := ansi.Strip(m.renderContent())
+		var marked []string
+		for _, line := range strings.Split(out, "\n") {
+			if strings.Contains(line, wrapMark) {
+				marked = append(marked, strings.TrimSpace(line))
+			}
+		}
+		// Only the long paragraph wraps, onto two more lines.
+		if len(marked) != 2 || !strings.HasSuffix(marked[1], "end.") {
+			t.Errorf("raw=%v: wrapped lines = %q", raw, marked)
+		}
+
+		// In the rendered view the marks replace the margin, so the
+		// placeholders stay where they were.
+		if !raw {
+			m.showWraps = false
+			if plain := ansi.Strip(m.renderContent()); strings.ReplaceAll(out, wrapMark, " ") != plain {
+				t.Errorf("marks moved the text:\n%s\nwithout marks:\n%s", out, plain)
+			}
+		}
+	}
+}
+
+func TestEditorReloadConfirmsDroppedValues(t *testing.T) {
+	path := writeLetter(t, "Dear [Company], re [Role].\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "Acme"
+	m.placeholders[1].Value = "Engineer"
+
+	if err := os.WriteFile(path, []byte("Dear [Company|Acme Corp], from [Name].\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyF5})
+	m = tm.(model)
+	if len(m.placeholders) != 2 || m.placeholders[1].Name != "Role" {
+		t.Fatalf("reload dropping Role went ahead without confirmation: %+v", m.placeholders)
+	}
+	if !strings.Contains(m.View(), "drops Role") {
+		t.Errorf("footer should warn about Role:\n%s", m.View())
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyF5})
+	m = tm.(model)
+	if m.rescanWarn != "" || len(m.placeholders) != 2 || m.placeholders[1].Name != "Name" {
+		t.Fatalf("second F5 should reload: %+v", m.placeholders)
+	}
+	// [Company] became [Company|Acme Corp] but kept its name, and value.
+	if got := m.placeholders[0].Value; got != "Acme" {
+		t.Errorf("Company = %q, want %q", got, "Acme")
+	}
+}
+
+func TestPlainMode(t *testing.T) {
+	styles := []*lipgloss.Style{&titleStyle, &statusStyle, &helpStyle, &errorStyle, &placeholderStyle, &activePlaceholderStyle,
+		&bracketStyle, &filledStyle, &timerStyle, &timerWarningStyle, &commentStyle, &wrapMarkStyle, &inputBoxStyle, &sidebarStyle}
+	saved := make([]lipgloss.Style, len(styles))
+	for i, s := range styles {
+		saved[i] = *s
+	}
+	t.Cleanup(func() {
+		for i, s := range styles {
+			*s = saved[i]
+		}
+		filledMark, emptyMark, cursorMark = "✓", "✗", "▶"
+	})
+	t.Cleanup(plain.Enable())
+	applyPlain()
+
+	m := initialModel(writeLetter(t, "# Letter\n\n> Dear [Company] and [Team]\n"), "brackets")
+	m.glamourStyle = plainGlamourStyle
+	m.placeholders[0].Value = "Acme"
+	m.showSidebar = true
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	view := tm.View()
+	if !strings.Contains(view, "Editing placeholder Team, 1 of 2 filled") {
+		t.Errorf("view should announce the field being edited:\n%s", view)
+	}
+	for _, step := range []struct {
+		msg  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Sales")}, "Editing placeholder Team, 1 of 2 filled"},
+		{tea.KeyMsg{Type: tea.KeyEnter}, "Filled Team, 2 of 2 filled"},
+		{tea.KeyMsg{Type: tea.KeyDown}, "Reading the letter, 2 of 2 filled"},
+	} {
+		tm, _ = tm.Update(step.msg)
+		if got := tm.View(); !strings.Contains(got, step.want) {
+			t.Errorf("after %s the view should say %q:\n%s", step.msg, step.want, got)
+		}
+	}
+	for _, r := range view {
+		if r == '\x1b' || r >= 0x2500 {
+			t.Fatalf("plain view contains %q:\n%s", r, view)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	dir := t.TempDir()
+	img := filepath.Join(dir, "my sig.png")
+	f, err := os.Create(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m := initialModel(writeLetter(t, "Regards,\n\n[Signature]\n"), "brackets")
+	updated, _ := m.Update(signatureMsg{path: img})
+	m = updated.(model)
+	want := "![signature](<" + img + ">)"
+	if got := m.placeholders[0].Value; got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+
+	md, err := convertLetter(m.filledText(), formatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note := "[Signature image: " + img + "]"; !strings.Contains(string(md), note) {
+		t.Errorf("markdown = %q, want the note %q", md, note)
+	}
+	html, err := convertLetter(m.filledText(), formatHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), `<img src="`) {
+		t.Errorf("html = %q, want an <img>", html)
+	}
+	withImage, err := convertLetter(m.filledText(), formatPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	without, err := convertLetter("Regards,\n", formatPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withImage) <= len(without) {
+		t.Errorf("pdf with signature is %d bytes, no bigger than %d without", len(withImage), len(without))
+	}
+
+	none := initialModel(writeLetter(t, "[Name]\n"), "brackets")
+	if cmd := none.pickSignature(); cmd != nil || none.signatureErr == nil {
+		t.Errorf("pickSignature without a [signature] placeholder: cmd %v, err %v", cmd, none.signatureErr)
+	}
+}
+
+func TestEditorRenderErrorPanel(t *testing.T) {
+	m := initialModel(writeLetter(t, "# Hi [Name]\n"), "brackets")
+	m.glamourStyle = filepath.Join(t.TempDir(), "missing.json")
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = tm.(model)
+	if m.renderErr == nil {
+		t.Fatal("renderErr = nil for a missing style file")
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "render: ") || !strings.Contains(view, "# Hi") {
+		t.Errorf("view lacks the render error or the raw letter:\n%s", view)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = tm.(model)
+	if strings.Contains(ansi.Strip(m.View()), "render: ") {
+		t.Errorf("render error still shown after esc")
+	}
+}
+
+func TestExportANSI(t *testing.T) {
+	path := writeLetter(t, "# Letter\n\nDear [Company],\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	m = tm.(model)
+	if m.ansiErr != nil {
+		t.Fatal(m.ansiErr)
+	}
+
+	data, err := os.ReadFile(ansiPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zoneMarker.Match(data) {
+		t.Errorf("export keeps zone markers: %q", data)
+	}
+	if plain := ansi.Strip(string(data)); !strings.Contains(plain, "Dear Acme,") {
+		t.Errorf("export = %q, want the filled letter", plain)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "Wrote letter_filled.ansi") {
+		t.Error("footer doesn't report the export")
+	}
+}
+
+func TestExportPDF(t *testing.T) {
+	path := writeLetter(t, "# Letter\n\nDear [Company], re: [Role].\n")
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = tm.(model)
+	if m.pdfErr == nil || !strings.Contains(m.pdfErr.Error(), "Role") {
+		t.Fatalf("pdfErr = %v, want Role named as empty", m.pdfErr)
+	}
+	pdfPath := filledPath(path, formatPDF)
+	if _, err := os.Stat(pdfPath); !os.IsNotExist(err) {
+		t.Fatalf("PDF written with Role empty: %v", err)
+	}
+
+	m.placeholders[1].Value = "Engineer"
+	tm, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = tm.(model)
+	if m.pdfErr != nil {
+		t.Fatal(m.pdfErr)
+	}
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Errorf("%s isn't a PDF: %q", pdfPath, data[:min(len(data), 16)])
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "Exported PDF") {
+		t.Error("footer doesn't report the export")
+	}
+
+	// With -output the PDF goes where ctrl+s saves, as a .pdf.
+	m.output = filepath.Join(filepath.Dir(path), "{company}.md")
+	m.exportPDF()
+	if want := filepath.Join(filepath.Dir(path), "Acme.pdf"); m.pdfErr != nil || m.pdfSaved != want {
+		t.Errorf("with -output: exported %q (%v), want %q", m.pdfSaved, m.pdfErr, want)
+	}
+}
+
+func TestEditorDiff(t *testing.T) {
+	m := initialModel(writeLetter(t, "Dear [Company], re: [Role|Engineer], from [Name].\n"), "brackets")
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	m = tm.(model)
+	if m.diff == nil {
+		t.Fatal("ctrl+d didn't open the substitutions")
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{
+		"[Company]       → Acme",
+		"[Role|Engineer] → Engineer (default)",
+		"[Name]          → (empty)",
+		"Still empty:",
+		"• Name",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if tm.(model).diff != nil {
+		t.Error("ctrl+d again didn't go back to the letter")
+	}
+}
+
+func TestEditorColors(t *testing.T) {
+	saved := []lipgloss.Style{placeholderStyle, activePlaceholderStyle, filledStyle}
+	t.Cleanup(func() {
+		placeholderStyle, activePlaceholderStyle, filledStyle = saved[0], saved[1], saved[2]
+	})
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := applyColors(map[string]styleColors{"filled": {Foreground: "red"}}); err == nil {
+		t.Error("colour name accepted")
+	}
+	if err := applyColors(map[string]styleColors{"border": {Foreground: "#fff"}}); err == nil {
+		t.Error("unknown style accepted")
+	}
+
+	m := initialModel(writeLetter(t, "Dear [Company],\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	before := tm.(model).renderKey()
+	// A render still running in the background keeps the colours it
+	// started with (go test -race catches it reading the live ones).
+	rendered := make(chan tea.Msg)
+	go func(render tea.Cmd) { rendered <- render() }(tm.(model).renderLetter())
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlY},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyCtrlU},
+		{Type: tea.KeyRunes, Runes: []rune("#123456")},
+		{Type: tea.KeyEnter},
+	} {
+		tm, _ = tm.Update(msg)
+	}
+	<-rendered
+	m = tm.(model)
+	if m.colors == nil || m.colors.err != nil {
+		t.Fatalf("colour editor = %+v", m.colors)
+	}
+	if got := colorString(placeholderStyle.GetForeground()); got != "#123456" {
+		t.Errorf("placeholder foreground = %q, want #123456", got)
+	}
+	if m.renderKey() == before {
+		t.Error("colour change doesn't re-render the letter")
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if tm.(model).colors != nil {
+		t.Fatal("editor still open after saving")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "aign", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fg": "#123456"`) {
+		t.Errorf("config = %s", data)
+	}
+}
+
+func TestEditorFloatingInput(t *testing.T) {
+	letter := "# Letter\n\nDear [Company],\n\n" + strings.Repeat("Filler paragraph.\n\n", 30) + "Regards,\n[Your Name]\n"
+	m := initialModel(writeLetter(t, letter), "brackets")
+	m.floatInput = true
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = tm.(model)
+	if m.editing != 0 {
+		t.Fatalf("editing = %d, want 0", m.editing)
+	}
+
+	// [Company] is on screen: the box sits under it and the footer has no
+	// input of its own.
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	at := -1
+	for i, line := range lines {
+		if strings.Contains(line, "Dear ") {
+			at = i
+		}
+	}
+	if at < 0 || !strings.Contains(lines[at+1], "╭") {
+		t.Errorf("no input box under the placeholder:\n%s", strings.Join(lines, "\n"))
+	}
+	if strings.Contains(m.View(), "[Company]: ") {
+		t.Error("input also shown in the footer")
+	}
+
+	// Scrolled out of view, it goes back to the footer.
+	m.viewport.GotoBottom()
+	if !strings.Contains(ansi.Strip(m.View()), "[Company]: ") {
+		t.Error("off-screen placeholder's input isn't in the footer")
+	}
+}
+
+func TestExportAll(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := config.SetValue(exportFormatsConfigKey, []string{"md", "html", "txt"}); err != nil {
+		t.Fatal(err)
+	}
+	formats, err := loadExportFormats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeLetter(t, "# Letter\n\nDear [Company],\n")
+	// A directory where the text file should go makes that one fail.
+	if err := os.Mkdir(filledPath(path, formatText), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(path, "brackets")
+	m.placeholders[0].Value = "Acme"
+	m.exportFormats = formats
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = tm.(model)
+
+	for _, f := range []outputFormat{formatMarkdown, formatHTML} {
+		data, err := os.ReadFile(filledPath(path, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "Dear Acme,") {
+			t.Errorf("%s export = %q", f, data)
+		}
+	}
+	if len(m.exportErrs) != 1 || !strings.HasPrefix(m.exportErrs[0], "txt: ") {
+		t.Errorf("export errors = %q, want the txt failure", m.exportErrs)
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Wrote 2 of 3: letter_filled.md, letter_filled.html", "export: txt: "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("footer lacks %q", want)
+		}
+	}
+
+	if err := config.SetValue(exportFormatsConfigKey, []string{"md", "rtf"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadExportFormats(); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+// deliverMatches runs cmd and hands the model any filter matches it
+// produces, which a list works out in the background.
+func deliverMatches(tm tea.Model, cmd tea.Cmd) tea.Model {
+	if cmd == nil {
+		return tm
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			tm = deliverMatches(tm, c)
+		}
+	case list.FilterMatchesMsg:
+		tm, _ = tm.Update(msg)
+	}
+	return tm
+}
+
+func TestEditorModelPicker(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	models := []llmModel{
+		{Name: "local", Command: []string{"sh", "-c", "cat"}},
+		{Name: "missing", Command: []string{"aign-no-such-llm"}},
+	}
+	if err := config.SetValue(llmModelsConfigKey, models); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(writeLetter(t, "Dear [Company],\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if m.models == nil || m.models.err == nil {
+		t.Fatal("unavailable model accepted")
+	}
+	if !strings.Contains(m.View(), "aign-no-such-llm") {
+		t.Error("selector doesn't show the failure")
+	}
+
+	// Filtering narrows the list to the model typed; enter accepts the
+	// filter, then chooses.
+	var cmd tea.Cmd
+	for _, r := range "/loc" {
+		tm, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	tm = deliverMatches(tm, cmd)
+	if visible := tm.(model).models.list.VisibleItems(); len(visible) != 1 || visible[0].(llmModel).Name != "local" {
+		t.Fatalf("filtering for loc shows %v", visible)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if m.models != nil || m.llmModel != "local" {
+		t.Fatalf("models = %v, llmModel = %q", m.models, m.llmModel)
+	}
+	if got := llmCommand(); strings.Join(got, " ") != "sh -c cat" {
+		t.Errorf("llmCommand() = %q, want the chosen model's", got)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "🤖 local") {
+		t.Error("header doesn't show the model")
+	}
+}
+
+func TestEditorLongValues(t *testing.T) {
+	m := initialModel(writeLetter(t, "Dear [Company],\n\nI saw [Link] and [Summary:max=10].\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 60, Height: 30})
+	m = tm.(model)
+	m.placeholders[0].Value = strings.Repeat("Acme Widgets ", 12)
+	m.placeholders[1].Value = "https://example.com/" + strings.Repeat("x", 90)
+
+	out := m.renderContent()
+	for _, line := range strings.Split(out, "\n") {
+		if w := ansi.StringWidth(line); w > 60 {
+			t.Errorf("line is %d wide: %q", w, ansi.Strip(line))
+		}
+	}
+	if plain := ansi.Strip(out); !strings.Contains(plain, "Acme Widgets Acme") || !strings.Contains(plain, "https://example.com/xxx") {
+		t.Errorf("long values missing:\n%s", plain)
+	}
+
+	// Pasting past max= says how much was cut.
+	tm = m
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a summary far too long"), Paste: true})
+	m = tm.(model)
+	if m.placeholders[m.editing].Name != "Summary" {
+		t.Fatalf("editing %q", m.placeholders[m.editing].Name)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "12 characters cut at the 10 limit") {
+		t.Errorf("no truncation note:\n%s", ansi.Strip(m.View()))
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if strings.Contains(ansi.Strip(tm.(model).View()), "characters cut") {
+		t.Error("truncation note outlives the next key")
+	}
+}
+
+func TestEditorWhereUsed(t *testing.T) {
+	letter := "# Letter\n\nDear [Company],\n\n" + strings.Repeat("Filler paragraph.\n\n", 20) + "I would love to join [Company] as [Role].\n"
+	m := initialModel(writeLetter(t, letter), "brackets")
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	m = tm.(model)
+	if m.where == nil {
+		t.Fatal("where-used list didn't open")
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Company is used in 2 places", "L3:6: Dear [Company],", "L45:22: I would love to join [Company] as [Role]."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if m.where != nil {
+		t.Fatal("list still open after jumping")
+	}
+	if got := ansi.Strip(m.viewport.View()); !strings.Contains(got, "I would love to join Acme as") {
+		t.Errorf("viewport not at the second use:\n%s", got)
+	}
+}
+
+func TestEditorRevealPlaceholder(t *testing.T) {
+	letter := "Dear [Company],\n\n" + strings.Repeat("Filler paragraph.\n\n", 20) +
+		"I would join as [Role].\n\n" + strings.Repeat("More filler.\n\n", 20) + "Again, [Role].\n"
+	m := initialModel(writeLetter(t, letter), "brackets")
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+
+	// Tabbing past Company to Role, far below the top, scrolls the view to
+	// Role's first use, not its second.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = tm.(model)
+	if m.editing != 1 {
+		t.Fatalf("editing %d, want Role", m.editing)
+	}
+	if got := ansi.Strip(m.viewport.View()); !strings.Contains(got, "I would join as") {
+		t.Errorf("viewport not at Role's first use:\n%s", got)
+	}
+	offset := m.viewport.YOffset
+
+	// Once the render showing Role as edited lands, it is still in view.
+	out, err := m.renderContentErr()
+	tm, _ = tm.Update(renderedMsg{key: m.renderKey(), out: out, err: err})
+	if got := tm.(model).viewport.YOffset; got != offset {
+		t.Errorf("render moved the view from %d to %d", offset, got)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if got := ansi.Strip(tm.(model).viewport.View()); !strings.Contains(got, "Dear Acme") {
+		t.Errorf("viewport not back at Company:\n%s", got)
+	}
+}
+
+func TestEditorSaveSummary(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if !saveSummaryEnabled() {
+		t.Error("summary off by default")
+	}
+	if err := config.SetValue(saveSummaryConfigKey, false); err != nil {
+		t.Fatal(err)
+	}
+	if saveSummaryEnabled() {
+		t.Error("save_summary: false ignored")
+	}
+
+	path := writeLetter(t, "Dear [Company], from [Your Name]\n")
+	m := initialModel(path, "brackets")
+	m.saveSummary = true
+	m.placeholders[0].Value = "Acme"
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = tm.(model)
+	if m.summary == nil {
+		t.Fatal("no summary after saving")
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{filledPath(path, formatMarkdown), "Size        28 bytes", "Format      md", "1/2 placeholders filled", "P  Preview the letter"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("summary lacks %q:\n%s", want, view)
+		}
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = tm.(model)
+	if m.summary != nil || m.final == nil {
+		t.Error("p didn't move on to the preview")
+	}
+}
+
+func TestEditorWideValueClicks(t *testing.T) {
+	for _, value := range []string{"株式会社", "🎉 Party", "Café ÉLAN", "👍🏽 Good", "한국 회사"} {
+		m := initialModel(writeLetter(t, "Dear [Company] team, and [Role].\n"), "brackets")
+		m.placeholders[0].Value = value
+		var tm tea.Model = m
+		tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+		m = tm.(model)
+
+		// Find the value on screen, in cells as the terminal draws them.
+		row, start := -1, 0
+		for i, line := range strings.Split(ansi.Strip(m.viewport.View()), "\n") {
+			if at := strings.Index(line, "Dear "+value+" team,"); at >= 0 {
+				row, start = i, runewidth.StringWidth(line[:at+len("Dear ")])
+			}
+		}
+		if row < 0 {
+			t.Fatalf("%q not rendered in place:\n%s", value, ansi.Strip(m.viewport.View()))
+		}
+		y := m.viewportTop() + row
+		end := start + runewidth.StringWidth(value) - 1
+
+		for _, c := range []struct {
+			x    int
+			want int
+		}{{start - 1, -1}, {start, 0}, {end, 0}, {end + 1, -1}, {end + len(" team, and ") + 1, 1}} {
+			if got := m.placeholderAt(c.x, y); got != c.want {
+				t.Errorf("%q: click at %d hits %d, want %d", value, c.x, got, c.want)
+			}
+		}
+	}
+}
+
+func TestEditorTaskList(t *testing.T) {
+	m := initialModel(writeLetter(t, "To do:\n\n- [ ] Follow up with [Name]\n- [x] Attach CV\n"), "brackets")
+	if len(m.placeholders) != 1 || m.placeholders[0].Name != "Name" {
+		t.Fatalf("placeholders = %+v, want only Name", m.placeholders)
+	}
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = tm.(model)
+
+	// Click each checkbox where it is drawn.
+	click := func(box string) {
+		t.Helper()
+		for i, line := range strings.Split(ansi.Strip(m.viewport.View()), "\n") {
+			if at := strings.Index(line, box); at >= 0 {
+				x := runewidth.StringWidth(line[:at])
+				tm, _ = m.Update(tea.MouseMsg{X: x, Y: m.viewportTop() + i, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+				m = tm.(model)
+				return
+			}
+		}
+		t.Fatalf("%s not rendered:\n%s", box, ansi.Strip(m.viewport.View()))
+	}
+	click("☐")
+	if !strings.Contains(m.letterText, "- [x] Follow up") || m.saved {
+		t.Errorf("after ticking, letter = %q", m.letterText)
+	}
+	tm, _ = m.Update(m.renderLetter()())
+	m = tm.(model)
+	click("☑")
+	if out := m.filledText(); !strings.Contains(out, "- [ ] Follow up") || !strings.Contains(out, "- [x] Attach CV") {
+		t.Errorf("after unticking, output = %q", m.filledText())
+	}
+	if m.editing != -1 {
+		t.Errorf("clicking a checkbox started editing %d", m.editing)
+	}
+}
+
+func TestEditorPalette(t *testing.T) {
+	m := initialModel(writeLetter(t, "Dear [Company],\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p"), Alt: true})
+	m = tm.(model)
+	if m.palette == nil {
+		t.Fatal("alt+p didn't open the palette")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "AI model") || !strings.Contains(view, "Ctrl+B") {
+		t.Errorf("palette doesn't list actions with their keys:\n%s", view)
+	}
+
+	var cmd tea.Cmd
+	for _, r := range "raw" {
+		tm, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	tm = deliverMatches(tm, cmd)
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if m.palette != nil || !m.raw {
+		t.Errorf("palette = %v, raw = %v after choosing raw", m.palette, m.raw)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyF1})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = tm.(model); m.palette != nil {
+		t.Error("esc didn't close the palette")
+	}
+
+	// Every key the palette can send comes back as that key.
+	km := defaultKeyMap()
+	for name, b := range km.actions() {
+		for _, k := range b.Keys() {
+			if got := palette.KeyMsg(k).String(); got != k {
+				t.Errorf("%s: KeyMsg(%q) reads as %q", name, k, got)
+			}
+		}
+	}
+}
+
+func TestEditorStreamingSuggestion(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stream := func(script string) {
+		t.Helper()
+		if err := config.SetValue(llmConfigKey, []string{"sh", "-c", script}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := initialModel(writeLetter(t, "Dear [Company],\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	// The reply shows as it arrives, then settles on its first line.
+	stream(`cat >/dev/null; printf '"Acme'; sleep 0.3; printf ' Corp"\nmore\n'`)
+	tm, cmd := tm.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	msg := cmd()
+	if chunk, ok := msg.(suggestChunkMsg); !ok || chunk.value != "Acme" {
+		t.Fatalf("first message = %#v, want the partial reply", msg)
+	}
+	tm, cmd = tm.Update(msg)
+	if m = tm.(model); m.textInput.Value() != "Acme" || !strings.Contains(m.View(), "Thinking") {
+		t.Errorf("input = %q while streaming", m.textInput.Value())
+	}
+	for m.suggest != nil {
+		tm, cmd = tm.Update(cmd())
+		m = tm.(model)
+	}
+	if m.textInput.Value() != "Acme Corp" || m.suggestionsUsed != 1 {
+		t.Errorf("input = %q, used = %d after the reply", m.textInput.Value(), m.suggestionsUsed)
+	}
+
+	// Esc stops a reply partway and puts back what was typed.
+	m.textInput.SetValue("typed")
+	stream(`cat >/dev/null; printf Glob; sleep 10; printf ex`)
+	tm, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	tm, _ = tm.Update(cmd())
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = tm.(model)
+	if m.suggest != nil || m.editing == -1 || m.textInput.Value() != "typed" {
+		t.Errorf("after esc: streaming = %v, editing = %d, input = %q", m.suggest != nil, m.editing, m.textInput.Value())
+	}
+	start := time.Now()
+	if msg := cmd(); time.Since(start) > 5*time.Second {
+		t.Errorf("cancelled model still running: %#v", msg)
+	}
+}
+
+func TestLetterHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	older := writeLetter(t, "Dear [Company],\n")
+	m := initialModel(writeLetter(t, "Dear [Company], re: [Role].\n"), "brackets")
+	m.history = true
+	m.noteHistory(false)
+	m.placeholders[0].Value = "Acme"
+	m.save()
+
+	// Not opened in a recording editor, and backdated.
+	if err := config.SetValue(historyConfigKey, map[string]letterRecord{
+		older:                      {Opened: 1, Filled: 1, Total: 1},
+		filepath.Join(older, "no"): {Opened: 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	m.noteHistory(true)
+
+	letters := recentLetters()
+	if len(letters) != 2 || letters[1].path != older {
+		t.Fatalf("recent = %+v, want this letter then the older one, without the missing file", letters)
+	}
+	if r := letters[0]; r.Filled != 1 || r.Total != 2 || r.SavedTo != m.savedPath || r.Saved == 0 {
+		t.Errorf("record = %+v", r)
+	}
+	if d := letters[0].Description(); !strings.Contains(d, "1/2 filled") || !strings.Contains(d, "saved ") {
+		t.Errorf("description = %q", d)
+	}
+	if d := letters[1].Description(); !strings.Contains(d, "complete") {
+		t.Errorf("description = %q", d)
+	}
+
+	var tm tea.Model = newLauncher(letters)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	if view := ansi.Strip(tm.View()); !strings.Contains(view, filepath.Base(older)) {
+		t.Errorf("launcher doesn't list the letters:\n%s", view)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, cmd := tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := tm.(launcher).chosen; got != older || cmd == nil {
+		t.Errorf("chosen = %q, want %q", got, older)
+	}
+}
+
+func TestEditorTypedPlaceholders(t *testing.T) {
+	m := initialModel(writeLetter(t, "From [Start:date], paid [Salary:number], remote: [Remote:bool]. See [Site:url].\n"), "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	if view := ansi.Strip(tm.View()); strings.Contains(view, ":date") || !strings.Contains(view, "[Site]") {
+		t.Errorf("types shown in the letter:\n%s", view)
+	}
+	keys := func(s string) {
+		for _, r := range s {
+			tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	// A date that isn't one keeps the input open; a relative one is
+	// worked out.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	keys("soon")
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = tm.(model); m.editing != 0 || !strings.Contains(m.View(), "not a date") {
+		t.Fatalf("bad date accepted: editing = %d", m.editing)
+	}
+	m.textInput.SetValue("+1d")
+	tm, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m = tm.(model); m.placeholders[0].Value != time.Now().AddDate(0, 0, 1).Format(dateLayout) {
+		t.Errorf("date = %q", m.placeholders[0].Value)
+	}
+
+	// Numbers refuse letters.
+	keys("12a5")
+	if m = tm.(model); m.textInput.Value() != "125" {
+		t.Errorf("number input = %q", m.textInput.Value())
+	}
+
+	// Bools toggle.
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	keys("y ")
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if m = tm.(model); m.textInput.Value() != "No" || !strings.Contains(ansi.Strip(m.View()), "Yes   No") {
+		t.Errorf("bool input = %q", m.textInput.Value())
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = tm.(model)
+	want := "paid 125, remote: No. See [Site]."
+	if got := m.filledText(); !strings.Contains(got, want) {
+		t.Errorf("filled = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestEditorReviewTyped(t *testing.T) {
+	m := initialModel(writeLetter(t, "From [Start:date], paid [Salary:number], remote: [Remote:bool].\n"), "brackets")
+	r := &reviewState{items: []suggestion{{index: 0}, {index: 1}, {index: 2}}, pending: 3, cancel: func() {}}
+	m.review = r
+	var tm tea.Model = m
+	for n, value := range []string{"+1d", "about 90k", "Yes"} {
+		tm, _ = tm.Update(reviewResultMsg{review: r, item: n, value: value})
+	}
+	keys := func(s string) {
+		for _, k := range strings.Split(s, " ") {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "clear":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlU}
+			}
+			tm, _ = tm.Update(msg)
+		}
+	}
+
+	// A suggestion that isn't a number can't be accepted as it is.
+	keys("y down y")
+	if s := tm.(model).review.items[1]; s.accepted || s.err == nil {
+		t.Fatalf("bad number accepted: %+v", s)
+	}
+
+	// Editing in the review keeps to the type: digits only, a yes/no toggle.
+	keys("e clear 9 a 0 enter down e n enter enter")
+	m = tm.(model)
+	if m.review != nil {
+		t.Fatalf("review still open: %+v", m.review.items)
+	}
+	want := []string{time.Now().AddDate(0, 0, 1).Format(dateLayout), "90", "No"}
+	for i, ph := range m.placeholders {
+		if ph.Value != want[i] {
+			t.Errorf("%s = %q, want %q", ph.Name, ph.Value, want[i])
+		}
+	}
+}
+
+func TestEditorProgress(t *testing.T) {
+	path := writeLetter(t, "Dear [Company], re: [Role].\n")
+	m := initialModel(path, "brackets")
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Acme")})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Quitting here and opening the letter again brings Acme back.
+	m = initialModel(path, "brackets")
+	if got := m.placeholders[0].Value; got != "Acme" {
+		t.Fatalf("restored Company = %q, want Acme", got)
+	}
+	if m.placeholders[1].Value != "" {
+		t.Errorf("Role restored as %q", m.placeholders[1].Value)
+	}
+
+	// A save with Role still empty keeps the progress; one with every
+	// placeholder filled removes it.
+	m.save()
+	if _, err := os.Stat(progressPath(path)); err != nil {
+		t.Fatalf("progress gone after a partial save: %v", err)
+	}
+	m.placeholders[1].Value = "Engineer"
+	m.save()
+	if _, err := os.Stat(progressPath(path)); !os.IsNotExist(err) {
+		t.Errorf("progress left after a complete save: %v", err)
+	}
+
+	if err := os.WriteFile(progressPath(path), []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if m := initialModel(path, "brackets"); m.placeholders[0].Value != "" {
+		t.Errorf("broken progress filled Company with %q", m.placeholders[0].Value)
+	}
+}
+
+func TestEditorStdinTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cover_letter.md")
+	m, err := stdinModel(strings.NewReader("Dear [Company],\n"), path, "brackets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Acme")})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = tm.(model)
+
+	data, err := os.ReadFile(filledPath(path, formatMarkdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Dear Acme,\n" {
+		t.Errorf("saved %q", data)
+	}
+	if _, err := os.Stat(progressPath(path)); !os.IsNotExist(err) {
+		t.Errorf("progress kept for a piped letter: %v", err)
+	}
+	m.reload(true)
+	if m.reloadErr != errStdinLetter {
+		t.Errorf("reloadErr = %v", m.reloadErr)
+	}
+
+	if _, err := stdinModel(strings.NewReader(" \n"), path, "brackets"); err == nil {
+		t.Error("empty stdin accepted")
+	}
+}
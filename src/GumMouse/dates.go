@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is how resolved relative dates appear in the letter.
+const dateLayout = "January 2, 2006"
+
+// resolveDefault returns a placeholder default as it should appear in the
+// letter. Relative date expressions are turned into dates; anything else is
+// used as written.
+func resolveDefault(def string, now time.Time) string {
+	if t, ok := parseRelativeDate(def, now); ok {
+		return t.Format(dateLayout)
+	}
+	return def
+}
+
+// parseRelativeDate understands:
+//
+//	today, tomorrow, yesterday
+//	+3d, -2w, +1m, +1y   (days, weeks, months, years from now)
+//	next monday           (the first such weekday after today)
+//
+// Matching is case-insensitive.
+func parseRelativeDate(expr string, now time.Time) (time.Time, bool) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch expr {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	if day, ok := strings.CutPrefix(expr, "next "); ok {
+		wd, ok := parseWeekday(strings.TrimSpace(day))
+		if !ok {
+			return time.Time{}, false
+		}
+		ahead := (int(wd)-int(today.Weekday())+6)%7 + 1
+		return today.AddDate(0, 0, ahead), true
+	}
+
+	if len(expr) < 3 || (expr[0] != '+' && expr[0] != '-') {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(expr[1 : len(expr)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if expr[0] == '-' {
+		n = -n
+	}
+	switch expr[len(expr)-1] {
+	case 'd':
+		return today.AddDate(0, 0, n), true
+	case 'w':
+		return today.AddDate(0, 0, 7*n), true
+	case 'm':
+		return today.AddDate(0, n, 0), true
+	case 'y':
+		return today.AddDate(n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRelativeDate(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2026, time.March, 4, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+		ok   bool
+	}{
+		{"today", "2026-03-04", true},
+		{"Tomorrow", "2026-03-05", true},
+		{"yesterday", "2026-03-03", true},
+		{"+14d", "2026-03-18", true},
+		{"-3d", "2026-03-01", true},
+		{"+2w", "2026-03-18", true},
+		{"+1m", "2026-04-04", true},
+		{"+1y", "2027-03-04", true},
+		{"next monday", "2026-03-09", true},
+		{"next wed", "2026-03-11", true},
+		{"next thursday", "2026-03-05", true},
+		{"next month", "", false},
+		{"+d", "", false},
+		{"+3x", "", false},
+		{"+-3d", "", false},
+		{"Acme Corp", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRelativeDate(tt.expr, now)
+		if ok != tt.ok {
+			t.Errorf("parseRelativeDate(%q) ok = %v, want %v", tt.expr, ok, tt.ok)
+			continue
+		}
+		if ok && got.Format("2006-01-02") != tt.want {
+			t.Errorf("parseRelativeDate(%q) = %s, want %s", tt.expr, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+func TestResolveDefault(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.UTC)
+
+	if got := resolveDefault("+7d", now); got != "March 11, 2026" {
+		t.Errorf("resolveDefault(+7d) = %q", got)
+	}
+	if got := resolveDefault("Hiring Manager", now); got != "Hiring Manager" {
+		t.Errorf("literal default changed to %q", got)
+	}
+}
+
+func TestPlaceholderDefault(t *testing.T) {
+	m := initialModel(writeLetter(t, "Reply by [Deadline|today] to [Name].\n"), "brackets")
+
+	ph := m.placeholders[0]
+	if ph.Label() != "Deadline" || ph.Default != "today" {
+		t.Fatalf("parsed %+v, label %q", ph, ph.Label())
+	}
+	if want := time.Now().Format(dateLayout); ph.Resolved() != want {
+		t.Errorf("Resolved() = %q, want %q", ph.Resolved(), want)
+	}
+
+	m.placeholders[0].Value = "Friday"
+	if got := m.placeholders[0].Resolved(); got != "Friday" {
+		t.Errorf("typed value should win over default, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// diffState is the list of substitutions a save would make, shown in
+// place of the letter.
+type diffState struct {
+	offset int // first row shown
+}
+
+// The substitution list's own keys.
+var (
+	diffUpKey   = key.NewBinding(key.WithKeys("up", "k"))
+	diffDownKey = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// updateDiff scrolls the substitution list, closing it on esc, q or the
+// key that opened it.
+func (m *model) updateDiff(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys.Cancel, m.keys.Diff, m.keys.Quit):
+		m.diff = nil
+	case key.Matches(msg, diffDownKey):
+		rows := len(m.diffRows())
+		m.diff.offset = min(m.diff.offset+1, max(rows-m.viewport.Height, 0))
+	case key.Matches(msg, diffUpKey):
+		m.diff.offset = max(m.diff.offset-1, 0)
+	}
+	return nil
+}
+
+// diffRows pairs each placeholder with what saving puts in its place,
+// followed by a warning naming the ones that would be left empty.
+func (m model) diffRows() []string {
+	width := 0
+	for _, ph := range m.placeholders {
+		width = max(width, cellWidth(ph.Original))
+	}
+	original := lipgloss.NewStyle().Width(width)
+
+	rows := []string{titleStyle.Render("Substitutions"), ""}
+	for _, ph := range m.placeholders {
+		value := errorStyle.Render("(empty)")
+		if v := ph.Resolved(); v != "" {
+			value = filledStyle.Render(v)
+			if ph.Value == "" {
+				value += helpStyle.Render(" (default)")
+			}
+		}
+		rows = append(rows, original.Render(ph.Original)+" → "+value)
+	}
+
+	if empty := m.emptyLabels(); len(empty) > 0 {
+		rows = append(rows, "", errorStyle.Render(plain.Icon("⚠️ ", "Warning: ")+"Still empty:"))
+		for _, name := range empty {
+			rows = append(rows, "  • "+name)
+		}
+	}
+	return rows
+}
+
+// diffView is the visible part of the substitution list.
+func (m model) diffView() string {
+	rows := m.diffRows()
+	rows = rows[min(m.diff.offset, len(rows)):]
+	rows = rows[:min(len(rows), m.viewport.Height)]
+	for i, row := range rows {
+		rows[i] = ansi.Truncate(row, m.viewport.Width, "…")
+	}
+	return strings.Join(rows, "\n")
+}
+
+// diffHelp is the footer while the substitution list is open.
+func (m model) diffHelp() string {
+	return "↑↓ = scroll • " + keyLabel(m.keys.Diff.Help().Key) + " = back to letter"
+}
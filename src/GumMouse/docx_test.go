@@ -0,0 +1,80 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLetterDOCX(t *testing.T) {
+	md := "# Cover Letter\n\n## Acme & Co\n\nDear team,\n\nI'd like the R&D job.\n"
+	data, err := convertLetter(md, formatDOCX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/_rels/document.xml.rels", "word/document.xml", "word/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("package lacks %s", name)
+		}
+	}
+
+	doc := parts["word/document.xml"]
+	for _, want := range []string{
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">Cover Letter</w:t>`,
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t xml:space="preserve">Acme &amp; Co</w:t>`,
+		`<w:p><w:r><w:t xml:space="preserve">Dear team,</w:t>`,
+		`<w:t xml:space="preserve">I&#39;d like the R&amp;D job.</w:t>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml lacks %s:\n%s", want, doc)
+		}
+	}
+	if n := strings.Count(doc, "<w:p>"); n != 4 {
+		t.Errorf("document.xml has %d paragraphs, want 4", n)
+	}
+
+	styles := parts["word/styles.xml"]
+	for _, want := range []string{
+		`w:styleId="Heading1"><w:name w:val="heading 1"/>`,
+		`<w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/>`,
+	} {
+		if !strings.Contains(styles, want) {
+			t.Errorf("styles.xml lacks %s:\n%s", want, styles)
+		}
+	}
+	if !strings.Contains(parts["[Content_Types].xml"], "/word/styles.xml") {
+		t.Error("content types don't declare styles.xml")
+	}
+}
+
+func TestFormatWithoutConverter(t *testing.T) {
+	_, err := parseFormat("odt")
+	if err == nil {
+		t.Fatal("odt accepted, but nothing converts to it")
+	}
+	if !strings.Contains(err.Error(), `"odt"`) || !strings.Contains(err.Error(), "docx") {
+		t.Errorf("error %q doesn't name the format and the ones available", err)
+	}
+	if f, err := parseFormat("DOCX"); err != nil || f != formatDOCX {
+		t.Errorf("parseFormat(DOCX) = %q, %v", f, err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// envPrefix marks a default taken from the environment, as in
+// [Name|env:APPLICANT_NAME].
+const envPrefix = "env:"
+
+// resolveEnvDefaults replaces env: defaults with the variable's value, or
+// nothing when it isn't set. Being defaults, they give way to a value
+// typed in, set over -server or filled from profile.json.
+func resolveEnvDefaults(placeholders []Placeholder) {
+	for i, ph := range placeholders {
+		name, ok := strings.CutPrefix(ph.Default, envPrefix)
+		if !ok {
+			continue
+		}
+		value, set := os.LookupEnv(strings.TrimSpace(name))
+		if !set {
+			slog.Debug("env default unset", "placeholder", ph.Label(), "var", name)
+		}
+		placeholders[i].Default = value
+	}
+}
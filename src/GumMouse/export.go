@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// outputFormat is a file type the filled letter can be saved as. Its value
+// doubles as the file extension.
+type outputFormat string
+
+const (
+	formatMarkdown outputFormat = "md"
+	formatText     outputFormat = "txt"
+	formatHTML     outputFormat = "html"
+	formatPDF      outputFormat = "pdf"
+	formatDOCX     outputFormat = "docx"
+)
+
+// outputFormats is the order ctrl+f cycles through.
+var outputFormats = []outputFormat{formatMarkdown, formatText, formatHTML, formatPDF, formatDOCX}
+
+func parseFormat(s string) (outputFormat, error) {
+	for _, f := range outputFormats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (want md, txt, html, pdf or docx)", s)
+}
+
+func (f outputFormat) next() outputFormat {
+	for i, g := range outputFormats {
+		if g == f {
+			return outputFormats[(i+1)%len(outputFormats)]
+		}
+	}
+	return formatMarkdown
+}
+
+// convertLetter turns the substituted markdown into the bytes of a file in
+// format f.
+// HTML and PDF show a signature image; the other formats name the file.
+func convertLetter(md string, f outputFormat) ([]byte, error) {
+	if f != formatHTML && f != formatPDF {
+		md = signatureNotes(md)
+	}
+	switch f {
+	case formatText:
+		return []byte(plainText(letterBlocks(md))), nil
+	case formatHTML:
+		var buf bytes.Buffer
+		if err := goldmark.Convert([]byte(md), &buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatPDF:
+		return letterPDF(letterBlocks(md))
+	case formatDOCX:
+		return letterDOCX(letterBlocks(md))
+	}
+	return []byte(md), nil
+}
+
+// textBlock is a heading or paragraph of the letter as plain text. Level is
+// the heading level, or 0 for body text. A paragraph holding nothing but
+// an image has its path in image instead.
+type textBlock struct {
+	level int
+	text  string
+	image string
+}
+
+// letterBlocks flattens markdown into headings and paragraphs, dropping
+// emphasis, link targets and other inline syntax.
+func letterBlocks(md string) []textBlock {
+	src := []byte(md)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var blocks []textBlock
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			blocks = append(blocks, textBlock{level: n.Level, text: inlineText(n, src)})
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph, *ast.TextBlock:
+			if img, ok := n.FirstChild().(*ast.Image); ok && n.ChildCount() == 1 {
+				blocks = append(blocks, textBlock{image: string(img.Destination)})
+				return ast.WalkSkipChildren, nil
+			}
+			prefix := ""
+			if _, ok := n.Parent().(*ast.ListItem); ok {
+				prefix = "• "
+			}
+			blocks = append(blocks, textBlock{text: prefix + inlineText(n, src)})
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			var b strings.Builder
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				b.Write(seg.Value(src))
+			}
+			blocks = append(blocks, textBlock{text: strings.TrimRight(b.String(), "\n")})
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return blocks
+}
+
+// inlineText collects the text under n, keeping line breaks.
+func inlineText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(src))
+			if c.HardLineBreak() || c.SoftLineBreak() {
+				b.WriteString("\n")
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.CodeSpan:
+			for t := c.FirstChild(); t != nil; t = t.NextSibling() {
+				if s, ok := t.(*ast.Text); ok {
+					b.Write(s.Segment.Value(src))
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+func plainText(blocks []textBlock) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			// Keep consecutive list items together.
+			if strings.HasPrefix(block.text, "• ") && strings.HasPrefix(blocks[i-1].text, "• ") {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(block.text)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// signatureWidth is how wide, in mm, an image is placed in the PDF.
+const signatureWidth = 50
+
+// letterPDF lays the letter out on A4-sized pages: headings in bold at a
+// size that shrinks with depth, body text in 11pt with a gap between
+// paragraphs, and images signatureWidth wide.
+func letterPDF(blocks []textBlock) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(25, 25, 25)
+	pdf.AddPage()
+	// The core fonts are Latin-1; map UTF-8 text so quotes and accents survive.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, b := range blocks {
+		if b.image != "" {
+			pdf.ImageOptions(b.image, -1, 0, signatureWidth, 0, true, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+			pdf.Ln(4)
+			continue
+		}
+		if b.level > 0 {
+			size := max(18-2*float64(b.level-1), 11)
+			pdf.SetFont("Helvetica", "B", size)
+			pdf.MultiCell(0, size*0.5, tr(b.text), "", "L", false)
+			pdf.Ln(3)
+			continue
+		}
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 5.5, tr(b.text), "", "L", false)
+		pdf.Ln(4)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"aign/render/config"
+)
+
+// exportFormatsConfigKey lists the formats ctrl+x writes, e.g.
+// {"export_formats": ["md", "pdf", "html"]}. Without it every format is
+// written.
+const exportFormatsConfigKey = "export_formats"
+
+// loadExportFormats reads the formats ctrl+x writes from config.
+func loadExportFormats() ([]outputFormat, error) {
+	var names []string
+	if !config.Value(exportFormatsConfigKey, &names) {
+		return outputFormats, nil
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: no formats given", exportFormatsConfigKey)
+	}
+	formats := make([]outputFormat, len(names))
+	for i, name := range names {
+		f, err := parseFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", exportFormatsConfigKey, err)
+		}
+		formats[i] = f
+	}
+	return formats, nil
+}
+
+// exportAll writes the letter once in each export format, carrying on past
+// failures so one bad converter doesn't cost the rest. With -output the
+// pattern's extension is swapped for each format's.
+func (m *model) exportAll() {
+	m.exported, m.exportErrs = nil, nil
+	for _, f := range m.exportFormats {
+		path, err := m.outputPath(f)
+		if err == nil {
+			if m.output != "" {
+				path = strings.TrimSuffix(path, filepath.Ext(path)) + "." + string(f)
+			}
+			err = m.writeLetter(path, f)
+		}
+		if err != nil {
+			slog.Error("export failed", "format", f, "err", err)
+			m.exportErrs = append(m.exportErrs, fmt.Sprintf("%s: %v", f, err))
+			continue
+		}
+		m.exported = append(m.exported, path)
+	}
+	if len(m.exported) > 0 {
+		m.saves++
+	}
+}
+
+// exportSummary is the footer's account of the last export: the files
+// written and, in errText, the formats that failed.
+func (m model) exportSummary() (status, errText string) {
+	if len(m.exported) > 0 {
+		names := make([]string, len(m.exported))
+		for i, path := range m.exported {
+			names[i] = filepath.Base(path)
+		}
+		status = fmt.Sprintf("Wrote %d of %d: %s", len(m.exported), len(m.exportFormats), strings.Join(names, ", "))
+	}
+	return status, strings.Join(m.exportErrs, "; ")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log/slog"
+
+	"aign/render"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openFinal shows the letter exactly as it will be saved, rendered on its
+// own over the whole screen for a last proofread: no header, footer or
+// placeholder highlighting.
+func (m *model) openFinal() {
+	vp := viewport.New(m.width, m.height)
+	vp.SetContent(m.finalContent(m.width))
+	m.final = &vp
+	slog.Debug("final preview opened")
+}
+
+// finalContent renders the filled letter at width, falling back to the
+// plain text if glamour can't render it.
+func (m model) finalContent(width int) string {
+	text := m.filledText()
+	out, err := render.Markdown(text, render.Options{Style: m.glamourStyle, Width: width})
+	if err != nil {
+		slog.Warn("final preview render failed", "err", err)
+		return text
+	}
+	return out
+}
+
+// updateFinal scrolls the final preview, closing it on esc, the preview
+// key or q.
+func (m *model) updateFinal(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			return tea.Quit
+		case key.Matches(msg, m.keys.Cancel, m.keys.Final, m.keys.Quit):
+			m.final = nil
+			return nil
+		}
+	case tea.WindowSizeMsg:
+		m.final.Width, m.final.Height = m.width, m.height
+		m.final.SetContent(m.finalContent(m.width))
+		return nil
+	}
+	var cmd tea.Cmd
+	*m.final, cmd = m.final.Update(msg)
+	return cmd
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// zoneStart is the escape sequence bubblezone puts either side of the
+// text marked with id, or "" when zones are off.
+func zoneStart(id string) string {
+	marked := zone.Mark(id, " ")
+	return marked[:strings.Index(marked, " ")]
+}
+
+// placeholderCell finds where the placeholder with the given zone ID
+// starts in the viewport's visible text, by its zone marker. ok is false
+// when it is scrolled out of view.
+func placeholderCell(view, id string) (row, col int, ok bool) {
+	marker := zoneStart(id)
+	if marker == "" {
+		return 0, 0, false
+	}
+	for row, line := range strings.Split(view, "\n") {
+		if i := strings.Index(line, marker); i >= 0 {
+			return row, cellWidth(line[:i]), true
+		}
+	}
+	return 0, 0, false
+}
+
+// floatWidth is the text width of the floating input, narrower than the
+// footer's so it covers less of the letter.
+const floatWidth = 24
+
+// floatingInput draws the editing input over the letter just below the
+// placeholder being edited, or just above it near the bottom of the
+// viewport. ok is false when the placeholder is off-screen or the box
+// doesn't fit, and the input belongs in the footer.
+func (m model) floatingInput(view string) (string, bool) {
+	row, col, ok := placeholderCell(view, m.placeholders[m.editing].ID)
+	if !ok {
+		return view, false
+	}
+	m.textInput.Width = floatWidth
+	box := inputBoxStyle.Render(m.inputView())
+	w, h := lipgloss.Size(box)
+	if w > m.viewport.Width || h+1 > m.viewport.Height {
+		return view, false
+	}
+	top := row + 1
+	if top+h > m.viewport.Height {
+		top = row - h
+	}
+	if top < 0 {
+		return view, false
+	}
+	return overlay(view, box, top, min(col, m.viewport.Width-w)), true
+}
+
+// overlay draws box over base with its top-left corner at row, col,
+// keeping what base has on either side.
+func overlay(base, box string, row, col int) string {
+	lines := strings.Split(base, "\n")
+	for i, b := range strings.Split(box, "\n") {
+		if row+i >= len(lines) {
+			break
+		}
+		line := lines[row+i]
+		left := ansi.Truncate(line, col, "")
+		left += strings.Repeat(" ", col-ansi.StringWidth(left))
+		right := ansi.TruncateLeft(line, col+ansi.StringWidth(b), "")
+		lines[row+i] = left + "\x1b[0m" + b + "\x1b[0m" + right
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"aign/render/config"
+	"aign/render/plain"
+	"aign/render/terminal"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// historyConfigKey is where the letters opened in the editor are kept
+	// in config, by absolute path, for -recent.
+	historyConfigKey = "letter_history"
+	// historyLimit caps how many letters the history remembers.
+	historyLimit = 100
+)
+
+// letterRecord is what the history knows of one letter: when it was last
+// opened and saved, in Unix seconds, and how many of its fields were
+// filled then.
+type letterRecord struct {
+	Opened  int64  `json:"opened"`
+	Saved   int64  `json:"saved,omitempty"`
+	SavedTo string `json:"saved_to,omitempty"`
+	Filled  int    `json:"filled"`
+	Total   int    `json:"total"`
+}
+
+// lastUsed is when the letter was last opened or saved.
+func (r letterRecord) lastUsed() int64 {
+	return max(r.Opened, r.Saved)
+}
+
+// loadHistory reads the history; a missing or unreadable one is empty.
+func loadHistory() map[string]letterRecord {
+	history := make(map[string]letterRecord)
+	config.Value(historyConfigKey, &history)
+	return history
+}
+
+// recordHistory notes the letter as opened now, or as saved when saved is
+// set, with how far it has got, dropping the oldest letters beyond
+// historyLimit.
+func (m model) recordHistory(saved bool) error {
+	path, err := filepath.Abs(m.filePath)
+	if err != nil {
+		return err
+	}
+	history := loadHistory()
+	r := history[path]
+	now := time.Now().Unix()
+	if saved {
+		r.Saved, r.SavedTo = now, m.savedPath
+	} else {
+		r.Opened = now
+	}
+	r.Filled, r.Total = m.filledCount(), len(m.placeholders)
+	history[path] = r
+
+	if len(history) > historyLimit {
+		paths := make([]string, 0, len(history))
+		for p := range history {
+			paths = append(paths, p)
+		}
+		sort.Slice(paths, func(i, j int) bool { return history[paths[i]].lastUsed() > history[paths[j]].lastUsed() })
+		for _, p := range paths[historyLimit:] {
+			delete(history, p)
+		}
+	}
+	return config.SetValue(historyConfigKey, history)
+}
+
+// noteHistory records the letter when the history is on, logging rather
+// than interrupting the editor if config can't be written.
+func (m model) noteHistory(saved bool) {
+	if !m.history {
+		return
+	}
+	if err := m.recordHistory(saved); err != nil {
+		slog.Warn("record history", "path", m.filePath, "err", err)
+	}
+}
+
+// recentLetter is a letter in the -recent launcher.
+type recentLetter struct {
+	path string
+	letterRecord
+}
+
+func (l recentLetter) FilterValue() string { return l.path }
+func (l recentLetter) Title() string       { return filepath.Base(l.path) }
+
+// Description gives how far the letter got, when it was last touched and
+// where it lives.
+func (l recentLetter) Description() string {
+	status := fmt.Sprintf("%d/%d filled", l.Filled, l.Total)
+	if l.Filled == l.Total {
+		status = plain.Icon("✅ ", "") + "complete"
+	}
+	when := "opened " + time.Unix(l.Opened, 0).Format("Jan 2 15:04")
+	if l.Saved >= l.Opened {
+		when = "saved " + time.Unix(l.Saved, 0).Format("Jan 2 15:04")
+	}
+	return status + " • " + when + " • " + filepath.Dir(l.path)
+}
+
+// recentLetters lists the letters in the history that still exist, most
+// recently used first.
+func recentLetters() []recentLetter {
+	var letters []recentLetter
+	for path, r := range loadHistory() {
+		if _, err := os.Stat(path); err == nil {
+			letters = append(letters, recentLetter{path, r})
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].lastUsed() > letters[j].lastUsed() })
+	return letters
+}
+
+// launcher is the -recent screen: the history in a list, enter to resume
+// the highlighted letter.
+type launcher struct {
+	list   list.Model
+	chosen string
+}
+
+func newLauncher(letters []recentLetter) launcher {
+	items := make([]list.Item, len(letters))
+	for i, l := range letters {
+		items[i] = l
+	}
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Recent letters"
+	l.SetStatusBarItemName("letter", "letters")
+	l.Styles.Title = titleStyle
+	return launcher{list: l}
+}
+
+func (l launcher) Init() tea.Cmd { return nil }
+
+func (l launcher) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		l.list.SetSize(msg.Width, msg.Height-1)
+	case tea.KeyMsg:
+		if l.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			if r, ok := l.list.SelectedItem().(recentLetter); ok {
+				l.chosen = r.path
+				return l, tea.Quit
+			}
+		case "esc":
+			if l.list.FilterState() == list.Unfiltered {
+				return l, tea.Quit
+			}
+		}
+	}
+	var cmd tea.Cmd
+	l.list, cmd = l.list.Update(msg)
+	return l, cmd
+}
+
+func (l launcher) View() string {
+	if len(l.list.Items()) == 0 {
+		return lipgloss.NewStyle().Margin(1, 2).Render(helpStyle.Render("No letters yet: open one in the editor and it will be listed here."))
+	}
+	return l.list.View()
+}
+
+// pickRecent runs the launcher, returning the letter chosen, if any.
+func pickRecent() (string, error) {
+	defer terminal.RestoreOnPanic(os.Stdout)
+
+	final, err := tea.NewProgram(newLauncher(recentLetters()), tea.WithAltScreen()).Run()
+	if err != nil {
+		return "", err
+	}
+	return final.(launcher).chosen, nil
+}
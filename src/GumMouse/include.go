@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// includeDirective on a line of its own pulls another file into the
+// letter, as in "@include signature.md". Paths are relative to the file
+// the directive is in.
+const includeDirective = "@include"
+
+// includeError is an @include line that couldn't be resolved.
+type includeError struct {
+	path   string // file holding the directive
+	line   int
+	target string
+	err    error
+}
+
+func (e *includeError) Error() string {
+	return fmt.Sprintf("%s:%d: %s %s: %v", e.path, e.line, includeDirective, e.target, e.err)
+}
+
+func (e *includeError) Unwrap() error { return e.err }
+
+// includeTarget returns the path named by an @include line.
+func includeTarget(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), includeDirective)
+	if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// loadTemplate reads the template at path with every @include line
+// replaced by the file it names, recursively. Failures to read path itself
+// are returned as they are; a missing or cyclic include is an
+// *includeError.
+func loadTemplate(path string) (string, error) {
+	return expandIncludes(path, nil)
+}
+
+func expandIncludes(path string, stack []string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := string(content)
+	if !strings.Contains(text, includeDirective) {
+		return text, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	stack = append(stack, abs)
+
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		name, ok := includeTarget(line)
+		if !ok {
+			continue
+		}
+		target := name
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		if t, err := filepath.Abs(target); err == nil && slices.Contains(stack, t) {
+			return "", &includeError{path, i + 1, name, fmt.Errorf("include cycle")}
+		}
+
+		included, err := expandIncludes(target, stack)
+		if err != nil {
+			return "", &includeError{path, i + 1, name, err}
+		}
+		if strings.HasSuffix(line, "\n") && !strings.HasSuffix(included, "\n") {
+			included += "\n"
+		}
+		lines[i] = included
+	}
+	return strings.Join(lines, ""), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"letter.md":              "Dear [Company],\n@include parts/body.md\nBye\n",
+		"parts/body.md":          "I am [Role].\n  @include sig/signature.md",
+		"parts/sig/signature.md": "[Your Name]\n",
+	})
+
+	m := initialModel(filepath.Join(dir, "letter.md"), "brackets")
+	if m.loadErr != nil {
+		t.Fatal(m.loadErr)
+	}
+	if want := "Dear [Company],\nI am [Role].\n[Your Name]\nBye\n"; m.letterText != want {
+		t.Errorf("letterText = %q, want %q", m.letterText, want)
+	}
+	if len(m.placeholders) != 3 || m.placeholders[2].Label() != "Your Name" {
+		t.Errorf("placeholders = %+v", m.placeholders)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"missing.md": "Hi\n@include nope.md\n",
+		"a.md":       "A\n@include b.md\n",
+		"b.md":       "B\n@include a.md\n",
+	})
+
+	_, err := loadTemplate(filepath.Join(dir, "missing.md"))
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "missing.md:2: @include nope.md") {
+		t.Errorf("missing include: %v", err)
+	}
+
+	_, err = loadTemplate(filepath.Join(dir, "a.md"))
+	if err == nil || !strings.Contains(err.Error(), "b.md:2: @include a.md: include cycle") {
+		t.Errorf("include cycle: %v", err)
+	}
+
+	if m := initialModel(filepath.Join(dir, "a.md"), "brackets"); m.loadErr == nil {
+		t.Error("initialModel should report the broken include")
+	}
+}
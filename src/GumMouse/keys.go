@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the editor's actions. Cancel, Commit and Suggest apply
+// while a placeholder is being edited, the rest while it isn't.
+type keyMap struct {
+	Quit       key.Binding
+	Cancel     key.Binding
+	Commit     key.Binding
+	Save       key.Binding
+	Format     key.Binding
+	Next       key.Binding
+	Prev       key.Binding
+	NextEmpty  key.Binding
+	Sidebar    key.Binding
+	Where      key.Binding
+	Spelling   key.Binding
+	Suggest    key.Binding
+	SuggestAll key.Binding
+	Raw        key.Binding
+	FirstEmpty key.Binding
+	Rescan     key.Binding
+	Reload     key.Binding
+	Final      key.Binding
+	Diff       key.Binding
+	Signature  key.Binding
+	Posting    key.Binding
+	ExportANSI key.Binding
+	ExportPDF  key.Binding
+	ExportAll  key.Binding
+	Colors     key.Binding
+	Model      key.Binding
+	Palette    key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:       key.NewBinding(key.WithKeys("ctrl+c", "q"), key.WithHelp("q", "quit")),
+		Cancel:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		Commit:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+		Save:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save")),
+		Format:     key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "format")),
+		Next:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next")),
+		Prev:       key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous")),
+		NextEmpty:  key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "next empty")),
+		Sidebar:    key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "fields")),
+		Where:      key.NewBinding(key.WithKeys("ctrl+w"), key.WithHelp("ctrl+w", "where used")),
+		Spelling:   key.NewBinding(key.WithKeys("ctrl+k"), key.WithHelp("ctrl+k", "spelling")),
+		Suggest:    key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "suggest")),
+		SuggestAll: key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "AI fill")),
+		Raw:        key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "raw")),
+		FirstEmpty: key.NewBinding(key.WithKeys("ctrl+home"), key.WithHelp("ctrl+home", "first empty")),
+		Rescan:     key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "rescan")),
+		Reload:     key.NewBinding(key.WithKeys("f5"), key.WithHelp("f5", "reload")),
+		// Terminals send ctrl+enter as plain enter, so the preview is on alt+enter.
+		Final:      key.NewBinding(key.WithKeys("alt+enter"), key.WithHelp("alt+enter", "final preview")),
+		Diff:       key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "substitutions")),
+		Signature:  key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "signature")),
+		Posting:    key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "job posting")),
+		ExportANSI: key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "export ANSI")),
+		ExportPDF:  key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "export PDF")),
+		ExportAll:  key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "export all")),
+		Colors:     key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "colours")),
+		Model:      key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "AI model")),
+		// ctrl+shift+p reaches us as ctrl+p, the PDF export.
+		Palette: key.NewBinding(key.WithKeys("alt+p", "f1"), key.WithHelp("alt+p", "commands")),
+	}
+}
+
+// actions names each binding as it appears in config.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":        &k.Quit,
+		"cancel":      &k.Cancel,
+		"commit":      &k.Commit,
+		"save":        &k.Save,
+		"format":      &k.Format,
+		"next":        &k.Next,
+		"prev":        &k.Prev,
+		"next_empty":  &k.NextEmpty,
+		"sidebar":     &k.Sidebar,
+		"where":       &k.Where,
+		"spelling":    &k.Spelling,
+		"suggest":     &k.Suggest,
+		"suggest_all": &k.SuggestAll,
+		"raw":         &k.Raw,
+		"first_empty": &k.FirstEmpty,
+		"rescan":      &k.Rescan,
+		"reload":      &k.Reload,
+		"final":       &k.Final,
+		"diff":        &k.Diff,
+		"signature":   &k.Signature,
+		"posting":     &k.Posting,
+		"export_ansi": &k.ExportANSI,
+		"export_pdf":  &k.ExportPDF,
+		"export_all":  &k.ExportAll,
+		"colors":      &k.Colors,
+		"model":       &k.Model,
+		"palette":     &k.Palette,
+	}
+}
+
+// helpText renders bindings as the footer shows them: "Ctrl+S = save".
+func helpText(bindings ...key.Binding) string {
+	parts := make([]string, len(bindings))
+	for i, b := range bindings {
+		parts[i] = keyLabel(b.Help().Key) + " = " + b.Help().Desc
+	}
+	return strings.Join(parts, " • ")
+}
+
+// keyLabel capitalizes a key name for display: "ctrl+n" becomes "Ctrl+N".
+func keyLabel(k string) string {
+	parts := strings.Split(k, "+")
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = strings.ToUpper(p)
+		} else if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,34 @@
+package main
+
+// linkPlaceholders gives each placeholder declared with same= the value
+// of the one it names, matched like profile fields, so filling one fills
+// both. While the source is being edited (editing is its index) its
+// linked copies follow what has been typed so far. A copy is cut to its
+// own max= like any other filled value.
+func linkPlaceholders(placeholders []Placeholder, editing int, typed string) {
+	sources := make(map[string]int, len(placeholders))
+	for i, ph := range placeholders {
+		if ph.Same == "" {
+			sources[normalizeField(ph.Label())] = i
+		}
+	}
+	for i := range placeholders {
+		ph := &placeholders[i]
+		if ph.Same == "" {
+			continue
+		}
+		ph.Linked = ""
+		j, ok := sources[normalizeField(ph.Same)]
+		if !ok {
+			continue
+		}
+		value := placeholders[j].Resolved()
+		if j == editing && typed != "" {
+			value = typed
+		}
+		if r := []rune(value); ph.MaxLen > 0 && len(r) > ph.MaxLen {
+			value = string(r[:ph.MaxLen])
+		}
+		ph.Linked = value
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aign/render/config"
+)
+
+// llmConfigKey overrides the command suggestions are requested from, as
+// an argument list that reads the prompt on stdin.
+const llmConfigKey = "llm_command"
+
+// suggestPrompt asks for a single placeholder's value, given its name and
+// the letter as filled in so far.
+const suggestPrompt = `You are helping complete a cover letter template.
+Reply with only the text to put in place of the placeholder %q: no quotes, no explanation.
+
+The letter so far:
+
+%s
+`
+
+// llmCommand is how the editor reaches the model: the one chosen from
+// llm_models, else llm_command. By default, like the shell tools, it
+// pipes the prompt into the project's llm_inference.py in chat mode, found
+// next to the editor binary or under src/ in the working directory.
+func llmCommand() []string {
+	if l, ok := activeModel(); ok {
+		return l.Command
+	}
+	var args []string
+	if config.Value(llmConfigKey, &args) && len(args) > 0 {
+		return args
+	}
+
+	script := filepath.Join("src", "llm_inference.py")
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), "..", "llm_inference.py")
+		if _, err := os.Stat(candidate); err == nil {
+			script = candidate
+		}
+	}
+	return []string{"python", script, "--chat"}
+}
+
+// suggestValue asks the model for a value for ph. The reply is cut to its
+// first line and to ph's length limit.
+func suggestValue(ctx context.Context, letter string, ph Placeholder) (string, error) {
+	return streamValue(ctx, letter, ph, nil)
+}
+
+// streamValue is suggestValue reporting the value as the model writes it:
+// partial, if set, gets the value so far each time more arrives.
+func streamValue(ctx context.Context, letter string, ph Placeholder, partial func(string)) (string, error) {
+	args := llmCommand()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf(suggestPrompt, ph.Label(), letter))
+	out := &replyWriter{ph: ph, partial: partial}
+	cmd.Stdout = out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// A cancelled model's children can otherwise hold its output open.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			lines := strings.Split(msg, "\n")
+			return "", fmt.Errorf("%v: %s", err, lines[len(lines)-1])
+		}
+		return "", err
+	}
+
+	value := replyValue(out.buf.String(), ph)
+	if value == "" {
+		return "", fmt.Errorf("empty reply")
+	}
+	return value, nil
+}
+
+// replyWriter collects the model's output, passing the value so far to
+// partial as it grows.
+type replyWriter struct {
+	buf     bytes.Buffer
+	ph      Placeholder
+	partial func(string)
+	last    string
+}
+
+func (w *replyWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	if w.partial != nil {
+		if value := replyValue(w.buf.String(), w.ph); value != w.last {
+			w.last = value
+			w.partial(value)
+		}
+	}
+	return len(p), nil
+}
+
+// replyValue is the value in a reply: its first non-blank line without
+// quotes, cut to ph's length limit.
+func replyValue(reply string, ph Placeholder) string {
+	value := ""
+	for _, line := range strings.Split(reply, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			value = strings.Trim(line, `"'`)
+			break
+		}
+	}
+	if r := []rune(value); ph.MaxLen > 0 && len(r) > ph.MaxLen {
+		value = string(r[:ph.MaxLen])
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"aign/render/config"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Config keys for choosing among models: llm_models names the commands
+// suggestions can be requested from, and llm_model is the one in use, e.g.
+//
+//	"llm_models": [
+//	  {"name": "local", "command": ["python", "src/llm_inference.py", "--chat", "--model", "smollm2-135m.gguf"]},
+//	  {"name": "cloud", "command": ["llm", "-m", "gpt-4o"]}
+//	],
+//	"llm_model": "cloud"
+const (
+	llmModelsConfigKey = "llm_models"
+	llmModelConfigKey  = "llm_model"
+)
+
+// llmModel is a named way of reaching a model: a command that reads the
+// prompt on stdin, like llm_command.
+type llmModel struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+}
+
+func (l llmModel) FilterValue() string { return l.Name }
+func (l llmModel) Title() string       { return l.Name }
+func (l llmModel) Description() string { return strings.Join(l.Command, " ") }
+
+// configuredModels lists the models in config.
+func configuredModels() []llmModel {
+	var models []llmModel
+	config.Value(llmModelsConfigKey, &models)
+	return models
+}
+
+// activeModel is the chosen model, if one is chosen and still configured.
+func activeModel() (llmModel, bool) {
+	var name string
+	if !config.Value(llmModelConfigKey, &name) {
+		return llmModel{}, false
+	}
+	for _, l := range configuredModels() {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return llmModel{}, false
+}
+
+// checkModel reports why l can't be used: no command, a program that isn't
+// on PATH or a --model file that isn't there.
+func checkModel(l llmModel) error {
+	if len(l.Command) == 0 {
+		return fmt.Errorf("%s: no command", l.Name)
+	}
+	if _, err := exec.LookPath(l.Command[0]); err != nil {
+		return fmt.Errorf("%s: %w", l.Name, err)
+	}
+	for i, arg := range l.Command[:len(l.Command)-1] {
+		if arg == "--model" {
+			if _, err := os.Stat(l.Command[i+1]); err != nil {
+				return fmt.Errorf("%s: model file: %w", l.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// modelPicker is the model selector: the configured models in a list,
+// with the reason the last one chosen couldn't be used.
+type modelPicker struct {
+	list list.Model
+	err  error
+}
+
+// openModels starts the model selector on the active model.
+func (m *model) openModels() {
+	models := configuredModels()
+	items := make([]list.Item, len(models))
+	active := 0
+	for i, l := range models {
+		items[i] = l
+		if l.Name == m.llmModel {
+			active = i
+		}
+	}
+	l := list.New(items, list.NewDefaultDelegate(), m.viewport.Width, m.viewport.Height)
+	l.Title = "Model for AI suggestions"
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("model", "models")
+	l.Styles.Title = titleStyle
+	l.Select(active)
+	m.models = &modelPicker{list: l}
+}
+
+// updateModels handles keys while the model selector is open. Enter
+// checks the highlighted model and, if it can be run, makes it the one
+// suggestions use from now on.
+func (m *model) updateModels(msg tea.KeyMsg) tea.Cmd {
+	p := m.models
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	if p.list.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.models = nil
+			return nil
+		case key.Matches(msg, m.keys.Commit):
+			l, ok := p.list.SelectedItem().(llmModel)
+			if !ok {
+				return nil
+			}
+			if p.err = checkModel(l); p.err != nil {
+				return nil
+			}
+			if p.err = config.SetValue(llmModelConfigKey, l.Name); p.err != nil {
+				slog.Error("save model", "err", p.err)
+				return nil
+			}
+			slog.Info("model chosen", "model", l.Name)
+			m.llmModel = l.Name
+			m.models = nil
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+	return cmd
+}
+
+// modelsView is the selector, or a note on how to set models up when
+// none are configured.
+func (m model) modelsView() string {
+	p := m.models
+	if len(p.list.Items()) == 0 {
+		return helpStyle.Render("No models configured. Add them under \"" + llmModelsConfigKey + "\" in the config file.")
+	}
+	view := p.list.View()
+	if p.err != nil {
+		view += "\n" + errorStyle.Render(p.err.Error())
+	}
+	return view
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// longValueWidth is the width past which a filled value is laid out a
+// word at a time, so it wraps with the letter instead of running off the
+// edge as one unbreakable token.
+const longValueWidth = 40
+
+// longValueTokens stands value in for placeholder id as one token per
+// word, recording the words drawn in style and clickable in marks. Tokens
+// are numbered from next; the number after the last is returned.
+func longValueTokens(id, value string, style lipgloss.Style, wrap, next int, marks map[string]string) ([]string, int) {
+	var tokens []string
+	for _, word := range valueWords(value, wrap) {
+		styled := style.Render(word)
+		token := placeholderToken(next, cellWidth(styled))
+		next++
+		marks[token] = zone.Mark(id, styled)
+		tokens = append(tokens, token)
+	}
+	return tokens, next
+}
+
+// valueWords splits a long value into words, breaking those too wide for
+// a line at wrap.
+func valueWords(value string, wrap int) []string {
+	var words []string
+	for _, line := range strings.Split(ansi.Wrap(value, max(wrap-4, 1), ""), "\n") {
+		words = append(words, strings.Fields(line)...)
+	}
+	return words
+}
+
+// noteTruncation records how much of what was just typed or pasted into
+// the input the character limit cut off, given the value's length before.
+func (m *model) noteTruncation(msg tea.Msg, before int) {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return
+	}
+	m.truncated = 0
+	if k.Type != tea.KeyRunes {
+		return
+	}
+	if cut := before + len(k.Runes) - len([]rune(m.textInput.Value())); cut > 0 {
+		m.truncated = cut
+	}
+}
+
+// truncationNote tells how much the last paste lost to the limit.
+func (m model) truncationNote() string {
+	if m.truncated == 0 {
+		return ""
+	}
+	return errorStyle.Render(fmt.Sprintf("%d characters cut at the %d limit", m.truncated, m.textInput.CharLimit))
+}
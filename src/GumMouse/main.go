@@ -1,6 +1,10 @@
+//go:build ignore
+
+// Mouse demo; run it on its own with: go run main.go
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"strings"
@@ -46,10 +50,12 @@ type model struct {
 	mouseMsg tea.MouseMsg
 	width    int
 	height   int
+	debug    bool
+	events   int
 }
 
-func initialModel() model {
-	return model{}
+func initialModel(debug bool) model {
+	return model{debug: debug}
 }
 
 func (m model) Init() tea.Cmd {
@@ -70,6 +76,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.MouseMsg:
 		m.mouseMsg = msg
+		m.events++
 	}
 
 	return m, nil
@@ -138,16 +145,90 @@ func (m model) View() string {
 		fmt.Sprintf("%s %s", labelStyle.Render("Last Button:"), valueStyle.Render(button)),
 		fmt.Sprintf("%s %s", labelStyle.Render("Modifiers:"), valueStyle.Render(modStr)),
 	)
+	box := infoBoxStyle
+	if m.debug {
+		info = lipgloss.JoinVertical(lipgloss.Left, info, "", m.debugInfo())
+		box = box.Width(60)
+	}
 
-	sb.WriteString(infoBoxStyle.Render(info))
+	sb.WriteString(box.Render(info))
 	sb.WriteString("\n")
 	sb.WriteString(instructionStyle.Render("Move, click, and scroll! • Press 'q' or 'esc' to exit"))
 
 	return sb.String()
 }
 
+// sgrButton is the button code an SGR (mode 1006) mouse report carries
+// for e: the button, plus 4 for shift, 8 for alt, 16 for ctrl and 32 for
+// motion. Bubble Tea decodes the report and drops the bytes, so this
+// rebuilds them.
+func sgrButton(e tea.MouseEvent) int {
+	var code int
+	switch e.Button {
+	case tea.MouseButtonLeft:
+		code = 0
+	case tea.MouseButtonMiddle:
+		code = 1
+	case tea.MouseButtonRight:
+		code = 2
+	case tea.MouseButtonNone:
+		code = 3
+	case tea.MouseButtonWheelUp:
+		code = 64
+	case tea.MouseButtonWheelDown:
+		code = 65
+	case tea.MouseButtonWheelLeft:
+		code = 66
+	case tea.MouseButtonWheelRight:
+		code = 67
+	case tea.MouseButtonBackward:
+		code = 128
+	case tea.MouseButtonForward:
+		code = 129
+	default:
+		code = 128 + int(e.Button) - int(tea.MouseButtonBackward)
+	}
+	if e.Shift {
+		code += 4
+	}
+	if e.Alt {
+		code += 8
+	}
+	if e.Ctrl {
+		code += 16
+	}
+	if e.Action == tea.MouseActionMotion {
+		code += 32
+	}
+	return code
+}
+
+// debugInfo is the -debug section of the info box: the event as an SGR
+// report, the units its coordinates are in and how many events arrived.
+func (m model) debugInfo() string {
+	e := tea.MouseEvent(m.mouseMsg)
+	raw := "-"
+	if m.events > 0 {
+		final := "M"
+		if e.Action == tea.MouseActionRelease {
+			final = "m"
+		}
+		// Reports count from 1; Bubble Tea counts from 0.
+		raw = fmt.Sprintf(`\x1b[<%d;%d;%d%s`, sgrButton(e), e.X+1, e.Y+1, final)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		fmt.Sprintf("%s %s", labelStyle.Render("Event:"), valueStyle.Render(e.String())),
+		fmt.Sprintf("%s %s", labelStyle.Render("SGR report:"), valueStyle.Render(raw)),
+		fmt.Sprintf("%s %s", labelStyle.Render("Units:"), valueStyle.Render(fmt.Sprintf("cells of %dx%d; no pixel reports", m.width, m.height))),
+		fmt.Sprintf("%s %s", labelStyle.Render("Events:"), valueStyle.Render(fmt.Sprint(m.events))),
+	)
+}
+
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithMouseCellMotion())
+	debug := flag.Bool("debug", false, "Also show each event as the SGR report it came from, the coordinate units and an event count")
+	flag.Parse()
+
+	p := tea.NewProgram(initialModel(*debug), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Error running program: %v", err)
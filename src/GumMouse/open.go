@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log/slog"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openedMsg reports whether the saved file could be handed to the
+// platform's default application.
+type openedMsg struct {
+	path string
+	err  error
+}
+
+// openerCommand opens path with the desktop's default handler.
+func openerCommand(path string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", path)
+	case "windows":
+		return exec.Command("cmd", "/c", "start", "", path)
+	default:
+		return exec.Command("xdg-open", path)
+	}
+}
+
+// openFile starts the default handler for path without waiting for it, so
+// the editor stays responsive while the viewer comes up. The process is
+// reaped in the background.
+func openFile(path string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := openerCommand(path)
+		if err := cmd.Start(); err != nil {
+			return openedMsg{path: path, err: err}
+		}
+		go func() {
+			if err := cmd.Wait(); err != nil {
+				slog.Warn("opener exited", "path", path, "err", err)
+			}
+		}()
+		slog.Debug("opened", "path", path, "cmd", cmd.Args[0])
+		return openedMsg{path: path}
+	}
+}
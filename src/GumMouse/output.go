@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// outputToken matches a {name} in an -output file name template.
+var outputToken = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// expandOutput fills each {name} in the -output template tmpl with the
+// value of the placeholder of that name, matched as profile fields are,
+// so {company} takes [Company]. Values are made safe for a file name
+// first; the rest of the template is used as written.
+func expandOutput(tmpl string, placeholders []Placeholder) (string, error) {
+	var err error
+	path := outputToken.ReplaceAllStringFunc(tmpl, func(tok string) string {
+		name := outputToken.FindStringSubmatch(tok)[1]
+		ph, ok := findField(placeholders, name)
+		if !ok {
+			err = fmt.Errorf("output: no placeholder named %q", name)
+			return tok
+		}
+		value := safeFileName(ph.Resolved())
+		if value == "" && err == nil {
+			err = fmt.Errorf("output: %s is empty", ph.Label())
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// checkOutput reports a {name} in tmpl that matches no placeholder, so a
+// typo in -output shows up before any editing is done.
+func checkOutput(tmpl string, placeholders []Placeholder) error {
+	for _, m := range outputToken.FindAllStringSubmatch(tmpl, -1) {
+		if _, ok := findField(placeholders, m[1]); !ok {
+			return fmt.Errorf("-output: no placeholder named %q", m[1])
+		}
+	}
+	return nil
+}
+
+func findField(placeholders []Placeholder, name string) (Placeholder, bool) {
+	key := normalizeField(name)
+	for _, ph := range placeholders {
+		if normalizeField(ph.Name) == key {
+			return ph, true
+		}
+	}
+	return Placeholder{}, false
+}
+
+// safeFileName makes a placeholder value usable as part of a file name:
+// path separators and characters Windows rejects are dropped, runs of
+// whitespace become one space and leading dots are removed so a value
+// can't produce a hidden file or "..".
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r) && !unicode.IsSpace(r):
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimLeft(strings.Join(strings.Fields(s), " "), ".")
+}
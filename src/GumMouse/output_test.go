@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestExpandOutput(t *testing.T) {
+	placeholders := []Placeholder{
+		{Name: "Company", Value: "Acme / Widgets  Inc."},
+		{Name: "Role Title", Value: " Senior\tEngineer "},
+		{Name: "Team", Default: "..Platform"},
+		{Name: "Date"},
+	}
+
+	got, err := expandOutput("out/{company}_{role_title}_{TEAM}.md", placeholders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "out/Acme Widgets Inc._Senior Engineer_Platform.md"; got != want {
+		t.Errorf("expandOutput = %q, want %q", got, want)
+	}
+
+	if _, err := expandOutput("{date}.md", placeholders); err == nil {
+		t.Error("expected an error for an empty placeholder")
+	}
+	if _, err := expandOutput("{salary}.md", placeholders); err == nil {
+		t.Error("expected an error for an unknown placeholder")
+	}
+	if err := checkOutput("{company}_{roletitle}.md", placeholders); err != nil {
+		t.Errorf("checkOutput: %v", err)
+	}
+	if err := checkOutput("{compnay}.md", placeholders); err == nil {
+		t.Error("checkOutput should catch a misspelt name")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"aign/render/palette"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteSkipped are the actions that only make sense while a placeholder
+// is being edited, which the palette can't be opened from.
+var paletteSkipped = map[string]bool{"cancel": true, "commit": true, "suggest": true, "palette": true}
+
+// openPalette lists every action in the key map in the command palette.
+func (m *model) openPalette() {
+	m.palette = palette.New(m.keys.actions(), paletteSkipped, palette.Options{
+		Width:      m.viewport.Width,
+		Height:     m.viewport.Height,
+		TitleStyle: titleStyle,
+		KeyLabel:   keyLabel,
+	})
+}
+
+// updatePalette handles keys while the palette is open. Choosing an
+// action closes it and returns the action's binding for the caller to
+// run.
+func (m *model) updatePalette(msg tea.KeyMsg) (*key.Binding, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return nil, tea.Quit
+	}
+	b, closed, cmd := m.palette.Update(msg)
+	if closed {
+		m.palette = nil
+	}
+	return b, cmd
+}
+
+// runAction runs b as if its first key had been pressed.
+func (m model) runAction(b key.Binding) (tea.Model, tea.Cmd) {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return m, nil
+	}
+	return m.update(palette.KeyMsg(keys[0]))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// emptyLabels names the placeholders still without a value.
+func (m model) emptyLabels() []string {
+	var names []string
+	for _, ph := range m.placeholders {
+		if ph.Resolved() == "" {
+			names = append(names, ph.Label())
+		}
+	}
+	return names
+}
+
+// exportPDF writes the filled letter as a PDF where ctrl+s would save it,
+// whatever format ctrl+s is set to: next to the template, or at -output
+// with its extension swapped for .pdf. A letter with placeholders still
+// empty isn't exported; the footer names them instead.
+func (m *model) exportPDF() {
+	if empty := m.emptyLabels(); len(empty) > 0 {
+		m.pdfErr = fmt.Errorf("fill %s before exporting a PDF", strings.Join(empty, ", "))
+		return
+	}
+	path, err := m.outputPath(formatPDF)
+	if err == nil {
+		if m.output != "" {
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + "." + string(formatPDF)
+		}
+		err = m.writeLetter(path, formatPDF)
+	}
+	m.pdfErr = err
+	if m.pdfErr != nil {
+		slog.Error("export pdf failed", "path", path, "err", m.pdfErr)
+		return
+	}
+	slog.Info("exported pdf", "path", path)
+	m.pdfSaved = path
+	m.saves++
+}
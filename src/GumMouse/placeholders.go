@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholderSyntax is a way of marking placeholders in a template, such as
+// [Name] or {{Name}}.
+type placeholderSyntax struct {
+	name        string
+	open, close string
+	pattern     *regexp.Regexp
+}
+
+var (
+	bracketSyntax  = placeholderSyntax{"brackets", "[", "]", regexp.MustCompile(`\[[^\]]+\]`)}
+	mustacheSyntax = placeholderSyntax{"mustache", "{{", "}}", regexp.MustCompile(`\{\{[^{}]+\}\}`)}
+	angleSyntax    = placeholderSyntax{"angle", "<", ">", regexp.MustCompile(`<[^<>\s/][^<>]*>`)}
+	dollarSyntax   = placeholderSyntax{"dollar", "${", "}", regexp.MustCompile(`\$\{[^{}]+\}`)}
+)
+
+// Escaped delimiters, as in \[literal\], are plain text. While
+// placeholders are found and substituted they are hidden behind these
+// runs of control bytes, one per byte of the escape, so offsets into the
+// text stay the same.
+const (
+	maskedOpen  = "\x00"
+	maskedClose = "\x01"
+)
+
+// mask hides the escaped delimiters in text from the placeholder pattern.
+func (s placeholderSyntax) mask(text string) string {
+	if s.open == "" || !strings.Contains(text, `\`) {
+		return text
+	}
+	text = strings.ReplaceAll(text, `\`+s.open, strings.Repeat(maskedOpen, len(s.open)+1))
+	return strings.ReplaceAll(text, `\`+s.close, strings.Repeat(maskedClose, len(s.close)+1))
+}
+
+// unmask undoes mask, leaving the delimiters escaped when escaped is set
+// and as bare literal text otherwise.
+func (s placeholderSyntax) unmask(text string, escaped bool) string {
+	if s.open == "" || !strings.ContainsAny(text, maskedOpen+maskedClose) {
+		return text
+	}
+	open, close := s.open, s.close
+	if escaped {
+		open, close = `\`+open, `\`+close
+	}
+	text = strings.ReplaceAll(text, strings.Repeat(maskedOpen, len(s.open)+1), open)
+	return strings.ReplaceAll(text, strings.Repeat(maskedClose, len(s.close)+1), close)
+}
+
+// find returns the placeholders in text, skipping escaped ones and task
+// list checkboxes.
+func (s placeholderSyntax) find(text string) []string {
+	masked := s.mask(text)
+	var found []string
+	for _, loc := range s.pattern.FindAllStringIndex(masked, -1) {
+		if isTaskBox(masked, loc[0], loc[1]) {
+			continue
+		}
+		found = append(found, text[loc[0]:loc[1]])
+	}
+	return found
+}
+
+// placeholderSyntaxes lists the syntaxes in order of preference when
+// detectSyntax finds a tie.
+var placeholderSyntaxes = []placeholderSyntax{bracketSyntax, mustacheSyntax, angleSyntax, dollarSyntax}
+
+// styleAuto asks initialModel to pick the style from the template itself.
+const styleAuto = "auto"
+
+func lookupSyntax(name string) (placeholderSyntax, bool) {
+	for _, s := range placeholderSyntaxes {
+		if s.name == strings.ToLower(name) {
+			return s, true
+		}
+	}
+	return placeholderSyntax{}, false
+}
+
+// syntaxFor resolves a -placeholder-style name for text, detecting the
+// syntax when the name is "auto".
+func syntaxFor(name, text string) placeholderSyntax {
+	if s, ok := lookupSyntax(name); ok {
+		return s
+	}
+	return detectSyntax(text)
+}
+
+// detectSyntax picks the syntax with the most placeholders in text.
+func detectSyntax(text string) placeholderSyntax {
+	best, count := bracketSyntax, 0
+	for _, s := range placeholderSyntaxes {
+		if n := len(s.find(text)); n > count {
+			best, count = s, n
+		}
+	}
+	return best
+}
+
+// optionPattern matches one option after a placeholder's name: a length
+// limit as in [Summary:max=280], a tab position as in [Name:order=1], a
+// link to another placeholder as in [Organization:same=Company] or a type
+// as in [StartDate:date]. A word that isn't a known type only counts as
+// one, falling back to text, when it follows the colon directly, so
+// [Note: optional] stays a name.
+var optionPattern = regexp.MustCompile(`^(.*?)(?:\s*:\s*(?:(max|order)\s*=\s*(\d+)|same\s*=\s*([^:]*?)|(text|date|number|bool))|:([a-z]+))\s*$`)
+
+// parseOptions splits the options off name, in any order, recording them
+// in ph.
+func parseOptions(name string, ph *Placeholder) string {
+	for {
+		m := optionPattern.FindStringSubmatch(name)
+		if m == nil || (m[2] == "" && m[4] == "" && m[5]+m[6] == "") {
+			return name
+		}
+		switch {
+		case m[2] == "max":
+			ph.MaxLen, _ = strconv.Atoi(m[3])
+		case m[2] == "order":
+			ph.Order, _ = strconv.Atoi(m[3])
+		case m[5]+m[6] != "":
+			ph.Type = placeholderType(m[5] + m[6])
+			ph.typeNote = name[len(m[1]):]
+		default:
+			ph.Same = m[4]
+		}
+		name = m[1]
+	}
+}
+
+// ParsePlaceholders finds the distinct placeholders in text written in
+// style, in order of first appearance. Escaped delimiters don't start or
+// end one.
+func ParsePlaceholders(text string, style placeholderSyntax) []Placeholder {
+	matches := style.find(text)
+
+	seen := make(map[string]bool)
+	var placeholders []Placeholder
+	for i, match := range matches {
+		if !seen[match] {
+			seen[match] = true
+			inner := strings.TrimSuffix(strings.TrimPrefix(match, style.open), style.close)
+			name, def, _ := strings.Cut(inner, "|")
+			ph := Placeholder{
+				ID:       fmt.Sprintf("ph-%d", i),
+				Original: match,
+				Default:  strings.TrimSpace(def),
+			}
+			ph.Name = strings.TrimSpace(parseOptions(name, &ph))
+			placeholders = append(placeholders, ph)
+		}
+	}
+	return placeholders
+}
+
+// printPlaceholderCount writes how many distinct placeholder names the
+// letter at path has, then the names one per line, for -count.
+func printPlaceholderCount(w io.Writer, path, styleName string) error {
+	content, err := loadTemplate(path)
+	if err != nil {
+		return err
+	}
+	text := stripComments(content)
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, ph := range ParsePlaceholders(text, syntaxFor(styleName, text)) {
+		if !seen[ph.Label()] {
+			seen[ph.Label()] = true
+			names = append(names, ph.Label())
+		}
+	}
+
+	fmt.Fprintln(w, len(names))
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
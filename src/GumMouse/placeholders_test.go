@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePlaceholders(t *testing.T) {
+	tests := []struct {
+		text   string
+		syntax placeholderSyntax
+		want   []Placeholder
+	}{
+		{
+			"Dear [Company], [Summary:max=280] [Date|today] [Company]",
+			bracketSyntax,
+			[]Placeholder{
+				{Original: "[Company]", Name: "Company"},
+				{Original: "[Summary:max=280]", Name: "Summary", MaxLen: 280},
+				{Original: "[Date|today]", Name: "Date", Default: "today"},
+			},
+		},
+		{
+			"Hi {{ Name }}, re: {{Role:max=40|Engineer}}",
+			mustacheSyntax,
+			[]Placeholder{
+				{Original: "{{ Name }}", Name: "Name"},
+				{Original: "{{Role:max=40|Engineer}}", Name: "Role", Default: "Engineer", MaxLen: 40},
+			},
+		},
+		{
+			"[Name:order=2] [Role:order=1:max=40] [Team:max=20 : order=3]",
+			bracketSyntax,
+			[]Placeholder{
+				{Original: "[Name:order=2]", Name: "Name", Order: 2},
+				{Original: "[Role:order=1:max=40]", Name: "Role", MaxLen: 40, Order: 1},
+				{Original: "[Team:max=20 : order=3]", Name: "Team", MaxLen: 20, Order: 3},
+			},
+		},
+		{
+			"[Company] [Organization:same=Company] [Org:max=10:same=Company]",
+			bracketSyntax,
+			[]Placeholder{
+				{Original: "[Company]", Name: "Company"},
+				{Original: "[Organization:same=Company]", Name: "Organization", Same: "Company"},
+				{Original: "[Org:max=10:same=Company]", Name: "Org", MaxLen: 10, Same: "Company"},
+			},
+		},
+		{
+			"${Name} at <Company>",
+			dollarSyntax,
+			[]Placeholder{{Original: "${Name}", Name: "Name"}},
+		},
+		{
+			"[Note: call back]",
+			bracketSyntax,
+			[]Placeholder{{Original: "[Note: call back]", Name: "Note: call back"}},
+		},
+		{
+			`As shown in \[Smith 2020\], [Company] leads. See \[1] and [Role].`,
+			bracketSyntax,
+			[]Placeholder{
+				{Original: "[Company]", Name: "Company"},
+				{Original: "[Role]", Name: "Role"},
+			},
+		},
+		{
+			"[StartDate:date] [Salary:number:max=10|0] [Remote : bool] [Site:url] [Re: Application] [Note: optional] [Time: morning]",
+			bracketSyntax,
+			[]Placeholder{
+				{Original: "[StartDate:date]", Name: "StartDate", Type: typeDate},
+				{Original: "[Salary:number:max=10|0]", Name: "Salary", Default: "0", MaxLen: 10, Type: typeNumber},
+				{Original: "[Remote : bool]", Name: "Remote", Type: typeBool},
+				{Original: "[Site:url]", Name: "Site", Type: typeText},
+				{Original: "[Re: Application]", Name: "Re: Application"},
+				{Original: "[Note: optional]", Name: "Note: optional"},
+				{Original: "[Time: morning]", Name: "Time: morning"},
+			},
+		},
+		{
+			`Use \{{literal\}} but fill {{Name}}`,
+			mustacheSyntax,
+			[]Placeholder{{Original: "{{Name}}", Name: "Name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := ParsePlaceholders(tt.text, tt.syntax)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParsePlaceholders(%q) found %d placeholders, want %d", tt.text, len(got), len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			g := got[i]
+			if g.Original != want.Original || g.Name != want.Name || g.Default != want.Default || g.MaxLen != want.MaxLen || g.Type != want.Type {
+				t.Errorf("ParsePlaceholders(%q)[%d] = %+v, want %+v", tt.text, i, g, want)
+			}
+		}
+	}
+}
+
+func TestDetectSyntax(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Dear {{Name}}, {{Company}} [link]", "mustache"},
+		{"Dear [Name], ${Company}", "brackets"},
+		{"No placeholders here.", "brackets"},
+		{"<Name> from <Company>", "angle"},
+	}
+	for _, tt := range tests {
+		if got := detectSyntax(tt.text).name; got != tt.want {
+			t.Errorf("detectSyntax(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEscapedDelimiters(t *testing.T) {
+	m := initialModel(writeLetter(t, `Per \[Smith 2020\], [Company] \[Company] grew.`+"\n"), "brackets")
+	if len(m.placeholders) != 1 {
+		t.Fatalf("placeholders = %+v, want only [Company]", m.placeholders)
+	}
+	m.placeholders[0].Value = "Acme"
+
+	if got, want := m.filledText(), "Per [Smith 2020], Acme [Company] grew.\n"; got != want {
+		t.Errorf("filledText() = %q, want %q", got, want)
+	}
+	if out := m.renderContent(); !strings.Contains(out, "[Smith 2020]") || strings.Contains(out, `\[`) {
+		t.Errorf("rendered view should show the brackets unescaped:\n%s", out)
+	}
+}
+
+func TestPrintSchema(t *testing.T) {
+	path := writeLetter(t, "%% not a [Field]\nDear [Company:order=1], re: [Role:max=40|Engineer].\n"+
+		"[Organization:same=Company] [Date|+3d] [Remote:bool] [Company]\n")
+	var out bytes.Buffer
+	if err := printSchema(&out, path, styleAuto); err != nil {
+		t.Fatal(err)
+	}
+	var got templateSchema
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, out.String())
+	}
+	want := templateSchema{Version: schemaVersion, Syntax: "brackets", Placeholders: []fieldSchema{
+		{Name: "Company", Type: "text", Order: 1},
+		{Name: "Role", Type: "text", Default: "Engineer", MaxLength: 40},
+		{Name: "Organization", Type: "text", SameAs: "Company"},
+		{Name: "Date", Type: "date", Default: "+3d"},
+		{Name: "Remote", Type: "bool"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema = %+v\nwant %+v", got, want)
+	}
+	if !strings.Contains(out.String(), `"schema_version": 1`) {
+		t.Errorf("no version in:\n%s", out.String())
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// plainGlamourStyle renders the letter with ASCII markup only.
+const plainGlamourStyle = "ascii"
+
+// cursorMark points at the selected row in the spelling and suggestion
+// lists.
+var cursorMark = "▶"
+
+// applyPlain switches every style to plain text for -plain, which also
+// leaves the mouse alone and says what is going on in a line of words
+// under the header.
+func applyPlain() {
+	flat := lipgloss.NewStyle()
+	titleStyle, statusStyle, helpStyle, errorStyle = flat, flat, flat, flat
+	placeholderStyle, activePlaceholderStyle, bracketStyle, filledStyle = flat, flat, flat, flat
+	timerStyle, timerWarningStyle, commentStyle, wrapMarkStyle = flat, flat, flat, flat
+	inputBoxStyle = flat
+	sidebarStyle = flat.Padding(0, 1)
+	filledMark, emptyMark, cursorMark = "[x]", "[ ]", ">"
+}
+
+// filledCount is how many placeholders have a value or a default.
+func (m model) filledCount() int {
+	n := 0
+	for _, ph := range m.placeholders {
+		if ph.Resolved() != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// progress counts the filled placeholders in words: "3 of 8 filled".
+func (m model) progress() string {
+	return fmt.Sprintf("%d of %d filled", m.filledCount(), len(m.placeholders))
+}
+
+// announcement describes in words what the editor is doing, for the
+// -plain status line: "Reading the letter, 3 of 8 filled". Starting or
+// ending an edit is said once in its place, until the next key.
+func (m model) announcement() string {
+	progress := m.progress()
+	var s string
+	switch {
+	case m.review != nil:
+		s = fmt.Sprintf("Reviewing AI suggestions, %d of %d ready", len(m.review.items)-m.review.pending, len(m.review.items))
+	case m.spellOpen:
+		s = fmt.Sprintf("Checking spelling, %d words flagged", len(m.spelling))
+	case m.editing != -1:
+		s = fmt.Sprintf("Editing placeholder %s, %s", m.placeholders[m.editing].Label(), progress)
+	default:
+		s = "Reading the letter, " + progress
+	}
+	switch {
+	case m.saveErr != nil:
+		s += ". Save failed"
+	case m.saved:
+		s += ". Saved to " + m.savedPath
+	}
+	return s
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// postingTimeout bounds fetching a job posting, and postingMaxBytes how
+// much of the page is read.
+const (
+	postingTimeout  = 15 * time.Second
+	postingMaxBytes = 2 << 20
+)
+
+// Placeholders a job posting fills, by normalized name. A bare "title" is
+// left alone: in a letter it is as likely to be "Ms." as a job.
+var (
+	companyFields = []string{"company", "companyname", "employer", "organization"}
+	roleFields    = []string{"role", "position", "jobtitle"}
+)
+
+// postingState is the job posting prompt: the URL being typed, then what
+// was found on the page, waiting to be applied. Messages carry it so a
+// fetch that finishes after the prompt was closed is dropped.
+type postingState struct {
+	input    textinput.Model
+	fetching bool
+	done     bool
+	company  string
+	role     string
+	err      error
+	cancel   context.CancelFunc
+}
+
+// postingMsg delivers what was read from a job posting.
+type postingMsg struct {
+	posting *postingState
+	company string
+	role    string
+	err     error
+}
+
+// postingApplyKey fills the placeholders once the result is shown.
+var postingApplyKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply"))
+
+// openPosting asks for the URL of a job posting.
+func (m *model) openPosting() tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.Placeholder = "https://…"
+	ti.CharLimit = 2048
+	m.posting = &postingState{input: ti}
+	return m.posting.input.Focus()
+}
+
+// closePosting stops a fetch in flight and leaves the prompt.
+func (m *model) closePosting() {
+	if m.posting.cancel != nil {
+		m.posting.cancel()
+	}
+	m.posting = nil
+}
+
+// updatePosting handles keys while the job posting prompt is open.
+func (m *model) updatePosting(msg tea.KeyMsg) tea.Cmd {
+	p := m.posting
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys.Cancel):
+		m.closePosting()
+		return nil
+	case p.fetching:
+		return nil
+	case p.done && key.Matches(msg, postingApplyKey):
+		n := m.applyPosting(p.company, p.role)
+		slog.Debug("posting applied", "company", p.company, "role", p.role, "filled", n)
+		m.closePosting()
+		return nil
+	case key.Matches(msg, m.keys.Commit):
+		url := strings.TrimSpace(p.input.Value())
+		if url == "" {
+			return nil
+		}
+		if !strings.Contains(url, "://") {
+			url = "https://" + url
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), postingTimeout)
+		p.cancel, p.fetching, p.done, p.err = cancel, true, false, nil
+		return func() tea.Msg {
+			defer cancel()
+			company, role, err := fetchPosting(ctx, url)
+			return postingMsg{posting: p, company: company, role: role, err: err}
+		}
+	}
+	// Typing after a result goes back to editing the URL.
+	p.done = false
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// setPosting shows what a fetch found, or why it failed, for confirmation.
+func (m *model) setPosting(msg postingMsg) {
+	p := msg.posting
+	if p != m.posting {
+		return
+	}
+	p.fetching = false
+	p.err = msg.err
+	if msg.err == nil && msg.company == "" && msg.role == "" {
+		p.err = fmt.Errorf("no company or role found on the page")
+	}
+	if p.err != nil {
+		slog.Warn("posting fetch failed", "url", p.input.Value(), "err", p.err)
+		return
+	}
+	p.company, p.role, p.done = msg.company, msg.role, true
+}
+
+// postingFill is a value a job posting puts in one placeholder.
+type postingFill struct {
+	index int
+	value string
+}
+
+// postingFills is what applyPosting puts in the company and role
+// placeholders, cut to their length limits.
+func (m model) postingFills(company, role string) []postingFill {
+	var fills []postingFill
+	for i, ph := range m.placeholders {
+		value := ""
+		switch name := normalizeField(ph.Label()); {
+		case slices.Contains(companyFields, name):
+			value = company
+		case slices.Contains(roleFields, name):
+			value = role
+		}
+		if value == "" {
+			continue
+		}
+		if r := []rune(value); ph.MaxLen > 0 && len(r) > ph.MaxLen {
+			value = string(r[:ph.MaxLen])
+		}
+		fills = append(fills, postingFill{i, value})
+	}
+	return fills
+}
+
+// applyPosting fills the company and role placeholders, cut to their
+// length limits, and returns how many it changed.
+func (m *model) applyPosting(company, role string) int {
+	fills := m.postingFills(company, role)
+	for _, f := range fills {
+		m.placeholders[f.index].Value = f.value
+	}
+	if len(fills) > 0 {
+		m.saved = false
+	}
+	return len(fills)
+}
+
+// postingReplaces names the values already filled in that applying the
+// posting would overwrite: `Company "Globex"`.
+func (m model) postingReplaces() []string {
+	var replaced []string
+	for _, f := range m.postingFills(m.posting.company, m.posting.role) {
+		if old := m.placeholders[f.index].Value; old != "" && old != f.value {
+			replaced = append(replaced, fmt.Sprintf("%s %q", m.placeholders[f.index].Label(), old))
+		}
+	}
+	return replaced
+}
+
+// postingView is the footer while the prompt is open: the URL input, then
+// what was found or what went wrong.
+func (m model) postingView() string {
+	p := m.posting
+	input := inputBoxStyle.Render(plain.Icon("🔗 ", "") + "Job posting URL: " + p.input.View())
+	cancel := keyLabel(m.keys.Cancel.Help().Key)
+	var status string
+	switch {
+	case p.fetching:
+		status = helpStyle.Render(plain.Icon("⏳ ", "") + "Fetching… • " + cancel + " = cancel")
+	case p.err != nil:
+		status = errorStyle.Render(plain.Icon("❌ ", "Error: ")+p.err.Error()) + helpStyle.Render(" • enter = try again • "+cancel+" = close")
+	case p.done:
+		found := fmt.Sprintf("Company: %s • Role: %s", orNone(p.company), orNone(p.role))
+		status = filledStyle.Render(found)
+		if replaced := m.postingReplaces(); len(replaced) > 0 {
+			status += " " + errorStyle.Render(plain.Icon("⚠️ ", "Warning: ")+"replaces "+strings.Join(replaced, ", "))
+		}
+		status += helpStyle.Render(" • " + helpText(postingApplyKey) + " • " + cancel + " = discard")
+	default:
+		status = helpStyle.Render("enter = fetch • " + cancel + " = cancel")
+	}
+	return input + "\n" + status
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(not found)"
+	}
+	return s
+}
+
+// fetchPosting downloads the job posting at url and reads the company and
+// role from it.
+func fetchPosting(ctx context.Context, url string) (company, role string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("User-Agent", "aign-cover-letter-editor")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, postingMaxBytes))
+	if err != nil {
+		return "", "", err
+	}
+	company, role = parsePosting(string(body))
+	return company, role, nil
+}
+
+var (
+	ldJSONPattern = regexp.MustCompile(`(?is)<script[^>]*type=["']application/ld\+json["'][^>]*>(.*?)</script>`)
+	metaPattern   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttr      = regexp.MustCompile(`(?is)\b(property|name|content)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	titlePattern  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	// Job boards append their own name to page titles.
+	siteSuffix = regexp.MustCompile(`\s+[|–—-]\s+[^|–—-]*$`)
+)
+
+// parsePosting reads the company and role from a job posting page. A
+// schema.org JobPosting is trusted first; failing that, the page's Open
+// Graph tags and title, which boards tend to write as "Role at Company".
+func parsePosting(page string) (company, role string) {
+	for _, m := range ldJSONPattern.FindAllStringSubmatch(page, -1) {
+		var data any
+		if json.Unmarshal([]byte(m[1]), &data) != nil {
+			continue
+		}
+		if c, r, ok := findJobPosting(data); ok {
+			return c, r
+		}
+	}
+
+	meta := make(map[string]string)
+	for _, tag := range metaPattern.FindAllString(page, -1) {
+		var name, content string
+		for _, a := range metaAttr.FindAllStringSubmatch(tag, -1) {
+			value := a[2] + a[3]
+			if strings.EqualFold(a[1], "content") {
+				content = value
+			} else {
+				name = strings.ToLower(value)
+			}
+		}
+		if name != "" {
+			meta[name] = clean(content)
+		}
+	}
+	company = meta["og:site_name"]
+	title := meta["og:title"]
+	if title == "" {
+		if m := titlePattern.FindStringSubmatch(page); m != nil {
+			title = clean(m[1])
+		}
+	}
+	if before, after, ok := strings.Cut(title, " at "); ok {
+		role = strings.TrimSpace(before)
+		if c := strings.TrimSpace(siteSuffix.ReplaceAllString(after, "")); c != "" {
+			company = c
+		}
+	} else {
+		role = strings.TrimSpace(siteSuffix.ReplaceAllString(title, ""))
+	}
+	return company, role
+}
+
+// findJobPosting looks through decoded JSON-LD, including @graph lists,
+// for a JobPosting and returns its hiring organization and title.
+func findJobPosting(data any) (company, role string, ok bool) {
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			if company, role, ok = findJobPosting(item); ok {
+				return company, role, ok
+			}
+		}
+	case map[string]any:
+		if t, _ := v["@type"].(string); t == "JobPosting" {
+			role, _ = v["title"].(string)
+			switch org := v["hiringOrganization"].(type) {
+			case map[string]any:
+				company, _ = org["name"].(string)
+			case string:
+				company = org
+			}
+			return clean(company), clean(role), true
+		}
+		if graph, found := v["@graph"]; found {
+			return findJobPosting(graph)
+		}
+	}
+	return "", "", false
+}
+
+// clean unescapes HTML entities and squeezes whitespace.
+func clean(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
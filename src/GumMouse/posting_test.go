@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParsePosting(t *testing.T) {
+	tests := []struct {
+		page          string
+		company, role string
+	}{
+		{
+			`<script type="application/ld+json">{"@context":"https://schema.org","@type":"JobPosting","title":"Staff Engineer","hiringOrganization":{"@type":"Organization","name":"Acme &amp; Co"}}</script>`,
+			"Acme & Co", "Staff Engineer",
+		},
+		{
+			`<script type="application/ld+json">{"@graph":[{"@type":"WebPage"},{"@type":"JobPosting","title":"Designer","hiringOrganization":"Globex"}]}</script>`,
+			"Globex", "Designer",
+		},
+		{
+			`<head><meta property="og:site_name" content="Initech"><meta property="og:title" content="Backend Developer"></head>`,
+			"Initech", "Backend Developer",
+		},
+		{
+			`<title>Data Analyst at Umbrella Corp | JobBoard</title>`,
+			"Umbrella Corp", "Data Analyst",
+		},
+		{`<p>nothing here</p>`, "", ""},
+	}
+	for _, tt := range tests {
+		company, role := parsePosting(tt.page)
+		if company != tt.company || role != tt.role {
+			t.Errorf("parsePosting(%q) = %q, %q, want %q, %q", tt.page, company, role, tt.company, tt.role)
+		}
+	}
+}
+
+func TestEditorPosting(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/job" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<title>Platform Engineer at Acme | Careers</title>`)
+	}))
+	defer srv.Close()
+
+	m := initialModel(writeLetter(t, "Dear [Company], re [Role:max=8] and [Title] [Name].\n"), "brackets")
+	var tm tea.Model = m
+	fetch := func(url string) {
+		t.Helper()
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(url)})
+		var cmd tea.Cmd
+		tm, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("enter started no fetch")
+		}
+		tm, _ = tm.Update(cmd())
+	}
+
+	// A failed fetch is reported and leaves the letter alone.
+	fetch(srv.URL + "/missing")
+	m = tm.(model)
+	if m.posting == nil || m.posting.err == nil || !strings.Contains(m.postingView(), "404") {
+		t.Fatalf("failed fetch not reported: %+v", m.posting)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// A good one is shown first, with what it would overwrite, and
+	// applied on enter.
+	m = tm.(model)
+	m.placeholders[0].Value = "Globex"
+	tm = m
+	fetch(srv.URL + "/job")
+	m = tm.(model)
+	if view := m.postingView(); !strings.Contains(view, "Company: Acme") || m.placeholders[0].Value != "Globex" {
+		t.Fatalf("result not shown for confirmation:\n%s", view)
+	}
+	if view := ansi.Strip(m.postingView()); !strings.Contains(view, `replaces Company "Globex"`) {
+		t.Errorf("confirmation doesn't say what is overwritten:\n%s", view)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	if m.posting != nil {
+		t.Error("prompt still open after apply")
+	}
+	want := []string{"Acme", "Platform", "", ""}
+	for i, ph := range m.placeholders {
+		if ph.Value != want[i] {
+			t.Errorf("%s = %q, want %q", ph.Name, ph.Value, want[i])
+		}
+	}
+}
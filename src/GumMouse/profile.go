@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"aign/render/config"
+)
+
+// profilePath is the applicant profile next to the config file:
+// $XDG_CONFIG_HOME/aign/profile.json. It maps field names to values, e.g.
+// {"Name": "Ada Lovelace", "Email": "ada@example.com"}.
+func profilePath() (string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "profile.json"), nil
+}
+
+// loadProfile reads the profile keyed by normalized field name. A missing
+// file is an empty profile.
+func loadProfile() (map[string]string, error) {
+	path, err := profilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	profile := make(map[string]string, len(fields))
+	for name, value := range fields {
+		profile[normalizeField(name)] = value
+	}
+	return profile, nil
+}
+
+// normalizeField folds a field name so "LinkedIn URL", "linkedin_url" and
+// "linkedinUrl" all match.
+func normalizeField(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// applyProfile fills the empty placeholders whose names are in profile,
+// cutting values to the placeholder's length limit, and returns how many
+// it filled.
+func applyProfile(placeholders []Placeholder, profile map[string]string) int {
+	filled := 0
+	for i, ph := range placeholders {
+		value, ok := profile[normalizeField(ph.Label())]
+		if !ok || value == "" || ph.Value != "" {
+			continue
+		}
+		if r := []rune(value); ph.MaxLen > 0 && len(r) > ph.MaxLen {
+			value = string(r[:ph.MaxLen])
+		}
+		placeholders[i].Value = value
+		filled++
+	}
+	return filled
+}
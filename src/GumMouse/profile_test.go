@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "aign"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	profile := `{"Your Name": "Ada Lovelace", "linkedin_url": "linkedin.com/in/ada", "Phone": ""}`
+	if err := os.WriteFile(filepath.Join(dir, "aign", "profile.json"), []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := loadProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	phs := ParsePlaceholders("[YOUR NAME] [LinkedIn URL:max=8] [Phone] [Company]", bracketSyntax)
+	if n := applyProfile(phs, p); n != 2 {
+		t.Errorf("filled %d fields, want 2", n)
+	}
+
+	want := []string{"Ada Lovelace", "linkedin", "", ""}
+	for i, ph := range phs {
+		if ph.Value != want[i] {
+			t.Errorf("%s = %q, want %q", ph.Label(), ph.Value, want[i])
+		}
+	}
+}
+
+func TestEnvDefaults(t *testing.T) {
+	t.Setenv("APPLICANT_NAME", "Ada Lovelace")
+	t.Setenv("APPLICANT_CITY", "London")
+	m := initialModel(writeLetter(t, "[Name|env:APPLICANT_NAME], [City|env: APPLICANT_CITY], [Phone|env:AIGN_UNSET_PHONE]\n"), "brackets")
+
+	want := []string{"Ada Lovelace", "London", ""}
+	for i, ph := range m.placeholders {
+		if got := ph.Resolved(); got != want[i] {
+			t.Errorf("%s = %q, want %q", ph.Label(), got, want[i])
+		}
+	}
+
+	// The profile fills the value, which comes before any default.
+	applyProfile(m.placeholders, map[string]string{"city": "Paris"})
+	if got := m.placeholders[1].Resolved(); got != "Paris" {
+		t.Errorf("City = %q, want the profile's Paris", got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+)
+
+// progressPath is the sidecar next to the template that keeps the values
+// typed so far, e.g. cover_letter.md.progress.json.
+func progressPath(letterPath string) string {
+	return letterPath + ".progress.json"
+}
+
+// loadProgress fills placeholders from the sidecar of letterPath, matching
+// them by their text in the template, and returns how many it filled. A
+// missing sidecar fills nothing.
+func loadProgress(placeholders []Placeholder, letterPath string) (int, error) {
+	data, err := os.ReadFile(progressPath(letterPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return 0, err
+	}
+	filled := 0
+	for i, ph := range placeholders {
+		if value, ok := values[ph.Original]; ok && value != "" {
+			placeholders[i].Value = value
+			filled++
+		}
+	}
+	return filled, nil
+}
+
+// saveProgress writes the values typed so far to the sidecar, keyed by
+// each placeholder's text in the template, so quitting early loses
+// nothing. Failing to write is logged rather than interrupting the edit.
+// A letter piped in on stdin has no file to keep progress beside.
+func (m model) saveProgress() {
+	if m.fromStdin {
+		return
+	}
+	values := make(map[string]string)
+	for _, ph := range m.placeholders {
+		if ph.Value != "" {
+			values[ph.Original] = ph.Value
+		}
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err == nil {
+		err = os.WriteFile(progressPath(m.filePath), append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		slog.Warn("save progress", "path", m.filePath, "err", err)
+	}
+}
+
+// clearProgress removes the sidecar once the letter is saved with every
+// placeholder filled, so stale values don't come back next time.
+func (m model) clearProgress() {
+	if m.filledCount() < len(m.placeholders) {
+		return
+	}
+	if err := os.Remove(progressPath(m.filePath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("clear progress", "path", m.filePath, "err", err)
+	}
+}
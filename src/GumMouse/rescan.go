@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// rescan finds the placeholders in the letter text again, so ones typed
+// into the body show up in the form; see reparse.
+func (m *model) rescan(force bool) {
+	m.reparse(m.letterText, force, m.keys.Rescan)
+}
+
+// reload reads the letter from disk again, so edits made to the file in
+// another editor show up; see reparse.
+func (m *model) reload(force bool) {
+	if m.fromStdin {
+		m.reloadErr = errStdinLetter
+		return
+	}
+	text, err := loadTemplate(m.filePath)
+	m.reloadErr = err
+	if err != nil {
+		slog.Warn("reload failed", "path", m.filePath, "err", err)
+		return
+	}
+	m.reparse(text, force, m.keys.Reload)
+}
+
+// reparse makes text the letter and finds its placeholders. Placeholders
+// whose text or name is unchanged keep their values; new ones start
+// empty. If removed ones would take unsaved values with them, it waits for
+// confirmation: it only goes ahead when force is set, which the editor
+// does for a second press of again.
+func (m *model) reparse(text string, force bool, again key.Binding) {
+	byText := make(map[string]string, len(m.placeholders))
+	byName := make(map[string]string, len(m.placeholders))
+	for _, ph := range m.placeholders {
+		byText[ph.Original] = ph.Value
+		byName[ph.Name] = ph.Value
+	}
+	var current string
+	if m.cursor != -1 {
+		current = m.placeholders[m.cursor].Name
+	}
+
+	placeholders := ParsePlaceholders(stripComments(text), m.syntax)
+	resolveEnvDefaults(placeholders)
+	kept := make(map[string]bool)
+	cursor := -1
+	for i := range placeholders {
+		ph := &placeholders[i]
+		if v, ok := byText[ph.Original]; ok {
+			ph.Value = v
+		} else {
+			ph.Value = byName[ph.Name]
+		}
+		kept[ph.Name] = true
+		if ph.Name == current {
+			cursor = i
+		}
+	}
+
+	var lost []string
+	for _, ph := range m.placeholders {
+		if ph.Value != "" && !kept[ph.Name] {
+			lost = append(lost, ph.Label())
+		}
+	}
+	if len(lost) > 0 && !m.saved && !force {
+		m.rescanWarn = again.Help().Desc + " drops " + strings.Join(lost, ", ") + "; press " + keyLabel(again.Help().Key) + " again to go ahead"
+		return
+	}
+
+	slog.Debug("rescanned letter", "path", m.filePath, "before", len(m.placeholders), "after", len(placeholders), "dropped", len(lost))
+	m.letterText = text
+	m.placeholders = placeholders
+	m.cursor = cursor
+	m.saved = false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// schemaVersion is bumped when a field of the -schema output changes
+// meaning or goes away; new fields can be added without bumping it.
+const schemaVersion = 1
+
+// templateSchema is what -schema prints: the letter's fields, for tools
+// that build a form to fill them.
+type templateSchema struct {
+	Version      int           `json:"schema_version"`
+	Syntax       string        `json:"syntax"`
+	Placeholders []fieldSchema `json:"placeholders"`
+}
+
+// fieldSchema describes one placeholder. Type is the one written, as in
+// [Salary:number], else "date" when the default is a relative date such
+// as "today" or "+3d", else "text".
+type fieldSchema struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	MaxLength int    `json:"max_length,omitempty"`
+	Order     int    `json:"order,omitempty"`
+	SameAs    string `json:"same_as,omitempty"`
+}
+
+// printSchema writes the schema of the letter at path as JSON, one field
+// per distinct placeholder name in order of appearance. Defaults are as
+// written, so env: ones name their variable rather than its value.
+func printSchema(w io.Writer, path, styleName string) error {
+	content, err := loadTemplate(path)
+	if err != nil {
+		return err
+	}
+	text := stripComments(content)
+	syntax := syntaxFor(styleName, text)
+
+	schema := templateSchema{Version: schemaVersion, Syntax: syntax.name, Placeholders: []fieldSchema{}}
+	seen := make(map[string]bool)
+	for _, ph := range ParsePlaceholders(text, syntax) {
+		if seen[ph.Label()] {
+			continue
+		}
+		seen[ph.Label()] = true
+		field := fieldSchema{
+			Name:      ph.Label(),
+			Type:      typeText,
+			Default:   ph.Default,
+			MaxLength: ph.MaxLen,
+			Order:     ph.Order,
+			SameAs:    ph.Same,
+		}
+		if ph.Type != "" {
+			field.Type = ph.Type
+		} else if _, ok := parseRelativeDate(ph.Default, time.Now()); ok {
+			field.Type = typeDate
+		}
+		schema.Placeholders = append(schema.Placeholders, field)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
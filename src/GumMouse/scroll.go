@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wheelColumns is how far one horizontal wheel step scrolls.
+const wheelColumns = 6
+
+// setContent shows rendered in the viewport, shifted left by the
+// horizontal scroll offset. The full render is kept for when the offset
+// changes.
+func (m *model) setContent(rendered string) {
+	m.content = rendered
+	m.contentWidth = lipgloss.Width(rendered)
+	m.scrollHorizontal(0)
+}
+
+// scrollHorizontal moves the view delta columns right, or left for a
+// negative delta, keeping the widest line's end in view.
+func (m *model) scrollHorizontal(delta int) {
+	m.xOffset = max(0, min(m.xOffset+delta, m.contentWidth-m.viewport.Width))
+	if m.xOffset == 0 {
+		m.viewport.SetContent(m.content)
+		return
+	}
+	lines := strings.Split(m.content, "\n")
+	for i, line := range lines {
+		lines[i] = shiftLeft(line, m.xOffset)
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// shiftLeft drops the first n columns of line but keeps every escape
+// sequence in them: the bubblezone start marker of a placeholder scrolled
+// partly out of view, so it stays clickable, and the style it opened, so
+// the rest of it keeps its colour. A wide character cut in half becomes
+// a space.
+func shiftLeft(line string, n int) string {
+	var b strings.Builder
+	var state byte
+	for len(line) > 0 {
+		seq, width, size, next := ansi.DecodeSequence(line, state, nil)
+		state = next
+		line = line[size:]
+		if width == 0 || n <= 0 {
+			b.WriteString(seq)
+			continue
+		}
+		n -= width
+		if n < 0 {
+			b.WriteString(strings.Repeat(" ", -n))
+		}
+	}
+	return b.String()
+}
+
+// revealPlaceholder scrolls the letter so the first use of placeholder i
+// is in view, leaving the scroll alone when it already is.
+func (m *model) revealPlaceholder(i int) {
+	line, ok := m.renderedLine(i, 0)
+	if !ok {
+		return
+	}
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(max(line-2, 0))
+	}
+}
+
+// wheelColumnsFor reports how far msg scrolls sideways: a horizontal wheel,
+// or the vertical wheel with shift held.
+func wheelColumnsFor(msg tea.MouseMsg) int {
+	if msg.Action != tea.MouseActionPress {
+		return 0
+	}
+	switch {
+	case msg.Button == tea.MouseButtonWheelLeft,
+		msg.Shift && msg.Button == tea.MouseButtonWheelUp:
+		return -wheelColumns
+	case msg.Button == tea.MouseButtonWheelRight,
+		msg.Shift && msg.Button == tea.MouseButtonWheelDown:
+		return wheelColumns
+	}
+	return 0
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// The -server protocol drives the substitution engine without the TUI, for
+// editor and IDE integrations. Each line on stdin is one JSON request and
+// gets exactly one JSON response line on stdout, in order:
+//
+//	{"id": 1, "method": "load", "params": {"path": "letter.md"}}
+//	{"id": 1, "result": {"style": "brackets", "placeholders": [...]}}
+//
+// id is any JSON value and is echoed back. A failed request gets
+// {"id": ..., "error": "message"} instead of a result; the server keeps
+// running. It exits when stdin closes.
+//
+// Methods:
+//
+//	load          params {"path"?, "text"?, "style"?}
+//	              Loads a template from path, or from text if given (path is
+//	              then only used to name the saved file). style is a
+//	              placeholder syntax name, default "auto". Profile values
+//	              are filled in unless -no-profile is set.
+//	              result {"style", "placeholders"}
+//	placeholders  result {"placeholders"}: each has "id", "name",
+//	              "original", "value", "default", "resolved" and, when
+//	              limited, "max".
+//	set           params {"values": {"<name or id>": "value", ...}}
+//	              Sets values by placeholder name or id; "" clears one.
+//	              Nothing is set if any name is unknown or a value is over
+//	              its limit. result {"placeholders"}
+//	render        params {"format"?}: md (default), txt or html.
+//	              result {"format", "content"}
+//	save          params {"path"?, "format"?}: format as for -format,
+//	              default md; path defaults to -output, or else the
+//	              template's _filled file. {name} tokens in path are
+//	              filled as for -output.
+//	              result {"path", "bytes"}
+type serverRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type serverResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// serverPlaceholder is a placeholder as the protocol reports it.
+type serverPlaceholder struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Original string `json:"original"`
+	Value    string `json:"value"`
+	Default  string `json:"default,omitempty"`
+	Resolved string `json:"resolved"`
+	Type     string `json:"type"`
+	Max      int    `json:"max,omitempty"`
+}
+
+// server is one -server session: the loaded template and its values.
+type server struct {
+	m       model
+	loaded  bool
+	profile map[string]string
+	output  string // -output template used when save has no path
+}
+
+// serve answers requests from r on w until r is exhausted.
+func (s *server) serve(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var req serverRequest
+		resp := serverResponse{}
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			resp.Error = fmt.Sprintf("bad request: %v", err)
+		} else {
+			resp.ID = req.ID
+			resp.Result, err = s.handle(req)
+			if err != nil {
+				resp.Error = err.Error()
+				resp.Result = nil
+			}
+		}
+		slog.Debug("server request", "method", req.Method, "err", resp.Error)
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func (s *server) handle(req serverRequest) (any, error) {
+	if req.Method != "load" && !s.loaded {
+		return nil, fmt.Errorf("no template loaded")
+	}
+	switch req.Method {
+	case "load":
+		var p struct {
+			Path  string  `json:"path"`
+			Text  *string `json:"text"`
+			Style string  `json:"style"`
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.load(p.Path, p.Text, p.Style)
+	case "placeholders":
+		return map[string]any{"placeholders": s.placeholders()}, nil
+	case "set":
+		var p struct {
+			Values map[string]string `json:"values"`
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if err := s.set(p.Values); err != nil {
+			return nil, err
+		}
+		return map[string]any{"placeholders": s.placeholders()}, nil
+	case "render":
+		var p struct {
+			Format string `json:"format"`
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		f, err := serverFormat(p.Format)
+		if err != nil {
+			return nil, err
+		}
+		if f == formatPDF || f == formatDOCX {
+			return nil, fmt.Errorf("%s is binary; use save", f)
+		}
+		data, err := convertLetter(s.m.filledText(), f)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"format": f, "content": string(data)}, nil
+	case "save":
+		var p struct {
+			Path   string `json:"path"`
+			Format string `json:"format"`
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		f, err := serverFormat(p.Format)
+		if err != nil {
+			return nil, err
+		}
+		data, err := convertLetter(s.m.filledText(), f)
+		if err != nil {
+			return nil, err
+		}
+		path := p.Path
+		if path == "" {
+			path = s.output
+		}
+		if path == "" {
+			path = filledPath(s.m.filePath, f)
+		} else if path, err = expandOutput(path, s.m.placeholders); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, err
+		}
+		slog.Info("saved", "path", path, "format", f, "bytes", len(data))
+		return map[string]any{"path": path, "bytes": len(data)}, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("bad params: %v", err)
+	}
+	return nil
+}
+
+func serverFormat(s string) (outputFormat, error) {
+	if s == "" {
+		return formatMarkdown, nil
+	}
+	return parseFormat(s)
+}
+
+// load replaces the session's template. Unlike the TUI, a missing file is
+// an error rather than the sample letter.
+func (s *server) load(path string, text *string, styleName string) (any, error) {
+	if styleName == "" {
+		styleName = styleAuto
+	}
+	if _, ok := lookupSyntax(styleName); !ok && styleName != styleAuto {
+		return nil, fmt.Errorf("unknown placeholder style %q", styleName)
+	}
+
+	var letterText string
+	switch {
+	case text != nil:
+		letterText = *text
+		if path == "" {
+			path = "cover_letter.md"
+		}
+	case path != "":
+		content, err := loadTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+		letterText = content
+	default:
+		return nil, fmt.Errorf("load needs a path or text")
+	}
+
+	body := stripComments(letterText)
+	style := syntaxFor(styleName, body)
+	s.m = model{
+		letterText:   letterText,
+		filePath:     path,
+		placeholders: ParsePlaceholders(body, style),
+		syntax:       style,
+		editing:      -1,
+		cursor:       -1,
+		format:       formatMarkdown,
+	}
+	resolveEnvDefaults(s.m.placeholders)
+	applyProfile(s.m.placeholders, s.profile)
+	linkPlaceholders(s.m.placeholders, -1, "")
+	s.loaded = true
+	slog.Debug("server loaded", "path", path, "style", style.name, "placeholders", len(s.m.placeholders))
+	return map[string]any{"style": style.name, "placeholders": s.placeholders()}, nil
+}
+
+func (s *server) placeholders() []serverPlaceholder {
+	out := make([]serverPlaceholder, 0, len(s.m.placeholders))
+	for _, ph := range s.m.placeholders {
+		typ := ph.Type
+		if typ == "" {
+			typ = typeText
+		}
+		out = append(out, serverPlaceholder{
+			ID:       ph.ID,
+			Name:     ph.Label(),
+			Original: ph.Original,
+			Value:    ph.Value,
+			Default:  ph.Default,
+			Resolved: ph.Resolved(),
+			Type:     typ,
+			Max:      ph.MaxLen,
+		})
+	}
+	return out
+}
+
+// set applies values keyed by placeholder name or id, all or nothing. A
+// name shared by placeholders with different delimiters sets them all.
+// Values are held to each placeholder's type as if typed in the editor.
+func (s *server) set(values map[string]string) error {
+	updated := append([]Placeholder(nil), s.m.placeholders...)
+	for key, raw := range values {
+		found := false
+		for i, ph := range updated {
+			if ph.ID != key && ph.Label() != key {
+				continue
+			}
+			value, err := checkTyped(ph, raw)
+			if err != nil {
+				return fmt.Errorf("%s: %v", ph.Label(), err)
+			}
+			if n := len([]rune(value)); ph.MaxLen > 0 && n > ph.MaxLen {
+				return fmt.Errorf("%s: value is %d characters, limit %d", ph.Label(), n, ph.MaxLen)
+			}
+			updated[i].Value = value
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("unknown placeholder %q", key)
+		}
+	}
+	linkPlaceholders(updated, -1, "")
+	s.m.placeholders = updated
+	return nil
+}
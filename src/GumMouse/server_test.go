@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerRoundTrip(t *testing.T) {
+	path := writeLetter(t, "Dear [Company],\n\nI am [Your Name] [Tagline:max=5].\n")
+	load, _ := json.Marshal(map[string]any{"id": 1, "method": "load", "params": map[string]string{"path": path}})
+
+	requests := strings.Join([]string{
+		string(load),
+		`{"id": 2, "method": "set", "params": {"values": {"Company": "Acme", "ph-1": "Ada"}}}`,
+		`{"id": 3, "method": "set", "params": {"values": {"Tagline": "far too long"}}}`,
+		`{"id": 4, "method": "set", "params": {"values": {"Nope": "x"}}}`,
+		`{"id": 5, "method": "render"}`,
+		`{"id": 6, "method": "save", "params": {"format": "txt"}}`,
+		`{"id": 7, "method": "frobnicate"}`,
+		`not json`,
+	}, "\n")
+
+	var out strings.Builder
+	s := &server{}
+	if err := s.serve(strings.NewReader(requests), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	type response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	var resps []response
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	for sc.Scan() {
+		var r response
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("bad response line %q: %v", sc.Text(), err)
+		}
+		resps = append(resps, r)
+	}
+	if len(resps) != 8 {
+		t.Fatalf("got %d responses, want 8:\n%s", len(resps), out.String())
+	}
+
+	var loaded struct {
+		Style        string              `json:"style"`
+		Placeholders []serverPlaceholder `json:"placeholders"`
+	}
+	if err := json.Unmarshal(resps[0].Result, &loaded); err != nil || resps[0].Error != "" {
+		t.Fatalf("load: %s %v", resps[0].Error, err)
+	}
+	if loaded.Style != "brackets" || len(loaded.Placeholders) != 3 || loaded.Placeholders[2].Max != 5 {
+		t.Errorf("load result = %+v", loaded)
+	}
+
+	if resps[1].Error != "" {
+		t.Errorf("set: %s", resps[1].Error)
+	}
+	for _, i := range []int{2, 3, 6, 7} {
+		if resps[i].Error == "" {
+			t.Errorf("request %d should fail: %s", resps[i].ID, resps[i].Result)
+		}
+	}
+
+	var rendered struct{ Content string }
+	json.Unmarshal(resps[4].Result, &rendered)
+	if want := "Dear Acme,\n\nI am Ada [Tagline:max=5].\n"; rendered.Content != want {
+		t.Errorf("render = %q, want %q", rendered.Content, want)
+	}
+
+	var saved struct{ Path string }
+	json.Unmarshal(resps[5].Result, &saved)
+	if want := filepath.Join(filepath.Dir(path), "letter_filled.txt"); saved.Path != want {
+		t.Errorf("saved to %q, want %q", saved.Path, want)
+	}
+	if _, err := os.Stat(saved.Path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerTyped(t *testing.T) {
+	s := &server{}
+	text := "From [Start:date], paid [Salary:number] at [Company].\n"
+	if _, err := s.load("", &text, "brackets"); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{typeDate, typeNumber, typeText} {
+		if got := s.placeholders()[i].Type; got != want {
+			t.Errorf("placeholder %d type = %q, want %q", i, got, want)
+		}
+	}
+
+	// One value that doesn't fit turns the whole set away.
+	if err := s.set(map[string]string{"Company": "Acme", "Salary": "banana"}); err == nil || !strings.Contains(err.Error(), "Salary") {
+		t.Errorf("set err = %v, want Salary refused", err)
+	}
+	if v := s.m.placeholders[2].Value; v != "" {
+		t.Errorf("Company = %q after a refused set", v)
+	}
+	if err := s.set(map[string]string{"Start": "+1d", "Salary": " 90000 "}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.m.placeholders[0].Value, time.Now().AddDate(0, 0, 1).Format(dateLayout); got != want {
+		t.Errorf("Start = %q, want %q", got, want)
+	}
+	if got := s.m.placeholders[1].Value; got != "90000" {
+		t.Errorf("Salary = %q, want it trimmed", got)
+	}
+}
+
+func TestServerNeedsLoad(t *testing.T) {
+	var out strings.Builder
+	s := &server{}
+	if err := s.serve(strings.NewReader(`{"id":"a","method":"render"}`+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); got != `{"id":"a","error":"no template loaded"}` {
+		t.Errorf("response = %s", got)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"aign/render/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerConfigKey overrides the command used to choose a signature image,
+// as an argument list that prints the chosen path on stdout.
+const pickerConfigKey = "picker_command"
+
+// signatureName is the placeholder a chosen signature image goes into.
+const signatureName = "signature"
+
+// signaturePattern matches the image reference signatureRef writes.
+var signaturePattern = regexp.MustCompile(`!\[signature\]\(<?([^)>]*)>?\)`)
+
+// signatureMsg carries the image chosen in the picker, or "" when it was
+// closed without a choice.
+type signatureMsg struct {
+	path string
+	err  error
+}
+
+// pickerCommand is the fuzzy picker, found next to the editor binary as
+// the installer lays them out, or else on $PATH.
+func pickerCommand() []string {
+	var args []string
+	if config.Value(pickerConfigKey, &args) && len(args) > 0 {
+		return args
+	}
+
+	picker := "fuzzy-picker"
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), "..", "GumFuzzy", "fuzzy-picker")
+		if _, err := os.Stat(candidate); err == nil {
+			picker = candidate
+		}
+	}
+	return []string{picker}
+}
+
+// signatureIndex is the index of the [signature] placeholder, or -1.
+func (m model) signatureIndex() int {
+	for i, ph := range m.placeholders {
+		if strings.EqualFold(ph.Name, signatureName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// pickSignature hands the terminal to the fuzzy picker so an image can be
+// chosen for the letter's [signature] placeholder.
+func (m *model) pickSignature() tea.Cmd {
+	m.signatureErr = nil
+	if m.signatureIndex() == -1 {
+		m.signatureErr = fmt.Errorf("the letter has no [%s] placeholder", signatureName)
+		return nil
+	}
+
+	args := pickerCommand()
+	cmd := exec.Command(args[0], args[1:]...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return signatureMsg{path: strings.TrimSpace(out.String()), err: err}
+	})
+}
+
+// setSignature fills the [signature] placeholder with a reference to the
+// image chosen in the picker.
+func (m *model) setSignature(msg signatureMsg) {
+	i := m.signatureIndex()
+	switch {
+	case msg.err != nil:
+		slog.Warn("signature picker failed", "err", msg.err)
+		m.signatureErr = msg.err
+	case msg.path == "" || i == -1:
+	default:
+		slog.Debug("signature chosen", "path", msg.path)
+		m.placeholders[i].Value = signatureRef(msg.path)
+		m.saved = false
+	}
+}
+
+// signatureRef is the markdown image reference for the picture at path.
+// Paths with spaces go in angle brackets so they stay one destination.
+func signatureRef(path string) string {
+	if strings.ContainsAny(path, " \t") {
+		path = "<" + path + ">"
+	}
+	return "![" + signatureName + "](" + path + ")"
+}
+
+// signatureNotes swaps signature images for a note naming the file, for
+// the formats that can't show a picture.
+func signatureNotes(md string) string {
+	return signaturePattern.ReplaceAllString(md, "[Signature image: $1]")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/client9/misspell"
+)
+
+// spellContext is how many runes of the line are shown either side of a
+// flagged word.
+const spellContext = 30
+
+var misspelledStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FF5F87")).
+	Underline(true)
+
+// The overlay's own navigation keys.
+var (
+	spellNextKey = key.NewBinding(key.WithKeys("down", "j", "n"))
+	spellPrevKey = key.NewBinding(key.WithKeys("up", "k", "p"))
+)
+
+// spellcheck returns the likely misspellings in text. Words that occur in
+// a placeholder's value are never flagged: they are mostly names, which
+// the dictionary can only get wrong.
+func spellcheck(text string, placeholders []Placeholder) []misspell.Diff {
+	r := misspell.New()
+
+	var ignore []string
+	for _, ph := range placeholders {
+		words := strings.FieldsFunc(strings.ToLower(ph.Resolved()), func(c rune) bool {
+			return !unicode.IsLetter(c) && c != '\''
+		})
+		ignore = append(ignore, words...)
+	}
+	if len(ignore) > 0 {
+		r.RemoveRule(ignore)
+		r.Compile()
+	}
+
+	_, diffs := r.Replace(text)
+	return diffs
+}
+
+// updateSpelling handles keys while the spelling overlay is open.
+func (m *model) updateSpelling(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys.Cancel, m.keys.Spelling, m.keys.Quit):
+		m.spelling = nil
+		m.spellOpen = false
+	case key.Matches(msg, spellNextKey):
+		if len(m.spelling) > 0 {
+			m.spellIndex = (m.spellIndex + 1) % len(m.spelling)
+		}
+	case key.Matches(msg, spellPrevKey):
+		if len(m.spelling) > 0 {
+			m.spellIndex = (m.spellIndex + len(m.spelling) - 1) % len(m.spelling)
+		}
+	}
+	return nil
+}
+
+// spellingView lists the flagged words in their line, keeping the
+// selected one in sight, for display in place of the letter.
+func (m model) spellingView() string {
+	if len(m.spelling) == 0 {
+		return filledStyle.Render(plain.Icon("✓ ", "") + "No spelling mistakes found")
+	}
+
+	rows := []string{helpStyle.Render(fmt.Sprintf("Spelling: %d of %d suspected", m.spellIndex+1, len(m.spelling))), ""}
+	visible := max(m.viewport.Height-len(rows), 1)
+	start := min(max(m.spellIndex-visible/2, 0), max(len(m.spelling)-visible, 0))
+	end := min(start+visible, len(m.spelling))
+
+	for i := start; i < end; i++ {
+		d := m.spelling[i]
+		marker := "  "
+		if i == m.spellIndex {
+			marker = activePlaceholderStyle.Render(cursorMark) + " "
+		}
+		row := fmt.Sprintf("%sL%d: %s → %s", marker, d.Line, spellingLine(d), filledStyle.Render(d.Corrected))
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(m.viewport.Width).Render(row))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// spellingLine is the flagged word underlined in a slice of its line.
+func spellingLine(d misspell.Diff) string {
+	line := strings.TrimRight(d.FullLine, "\r\n")
+	before := []rune(line[:d.Column])
+	after := []rune(line[d.Column+len(d.Original):])
+
+	if len(before) > spellContext {
+		before = append([]rune("…"), before[len(before)-spellContext:]...)
+	}
+	if len(after) > spellContext {
+		after = append(after[:spellContext], '…')
+	}
+	return strings.TrimLeft(string(before), " \t") + misspelledStyle.Render(d.Original) + string(after)
+}
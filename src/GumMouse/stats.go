@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// writeStats prints the -stats summary of a finished session. It is only
+// written to w once the editor has closed, and nothing is kept or sent
+// anywhere else.
+func writeStats(w io.Writer, m model, elapsed time.Duration) {
+	fmt.Fprintf(w, "Time spent:       %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(w, "Fields filled:    %d/%d\n", m.filledCount(), len(m.placeholders))
+	fmt.Fprintf(w, "Saves:            %d\n", m.saves)
+	fmt.Fprintf(w, "AI suggestions:   %d used\n", m.suggestionsUsed)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// errStdinLetter is why a letter piped in can't be reloaded.
+var errStdinLetter = errors.New("the letter was read from stdin, so there is no file to reload")
+
+// stdinPiped reports whether stdin is a pipe or file rather than the
+// terminal, so a template can be read from it.
+func stdinPiped() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice == 0
+}
+
+// stdinModel is the editor for a template read from r. It saves as if the
+// template were letterPath, keeps no progress sidecar and can't be
+// reloaded; @include lines are left as written.
+func stdinModel(r io.Reader, letterPath, styleName string) (model, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return model{}, err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return model{}, errors.New("no template on stdin")
+	}
+	m := letterModel(string(data), letterPath, styleName)
+	m.fromStdin = true
+	return m, nil
+}
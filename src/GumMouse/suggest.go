@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// suggestWorkers bounds how many suggestions are requested at once.
+const suggestWorkers = 3
+
+// suggestStream is a suggestion for the placeholder being edited, shown in
+// the input as it arrives. Messages carry it so that those arriving after
+// it was stopped are dropped.
+type suggestStream struct {
+	id     string
+	before string // the input's value when it started, put back on esc
+	cancel context.CancelFunc
+	chunks chan string
+	done   chan suggestMsg
+}
+
+// suggestChunkMsg is the suggestion so far.
+type suggestChunkMsg struct {
+	stream *suggestStream
+	value  string
+}
+
+// suggestMsg is the model's whole answer for the placeholder being edited.
+type suggestMsg struct {
+	stream *suggestStream
+	id     string
+	value  string
+	err    error
+}
+
+// suggestOne asks for a value for the placeholder being edited, streaming
+// it into the input as the model writes it.
+func (m *model) suggestOne() tea.Cmd {
+	ph := m.placeholders[m.editing]
+	letter := m.filledText()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &suggestStream{
+		id:     ph.ID,
+		before: m.textInput.Value(),
+		cancel: cancel,
+		chunks: make(chan string, 1),
+		done:   make(chan suggestMsg, 1),
+	}
+	m.suggest = s
+	go func() {
+		defer cancel()
+		value, err := streamValue(ctx, letter, ph, func(partial string) {
+			// Only the latest matters: replace one not yet shown. This is
+			// the only sender, so the buffer has room after the drain.
+			select {
+			case <-s.chunks:
+			default:
+			}
+			s.chunks <- partial
+		})
+		s.done <- suggestMsg{stream: s, id: ph.ID, value: value, err: err}
+	}()
+	return waitForSuggest(s)
+}
+
+func waitForSuggest(s *suggestStream) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case value := <-s.chunks:
+			return suggestChunkMsg{stream: s, value: value}
+		case msg := <-s.done:
+			return msg
+		}
+	}
+}
+
+// stopSuggest cancels a suggestion still arriving and puts back what the
+// input held before it.
+func (m *model) stopSuggest() {
+	if m.suggest == nil {
+		return
+	}
+	m.suggest.cancel()
+	m.textInput.SetValue(m.suggest.before)
+	m.textInput.CursorEnd()
+	m.suggest = nil
+}
+
+// suggestion is one field in the review list.
+type suggestion struct {
+	index    int
+	value    string
+	err      error
+	done     bool
+	accepted bool
+	rejected bool
+}
+
+// reviewState holds suggestions for every empty placeholder while they
+// are fetched and reviewed. Messages carry it so that answers arriving
+// after the review was closed are dropped.
+type reviewState struct {
+	items   []suggestion
+	cursor  int
+	editing bool
+	pending int
+	cancel  context.CancelFunc
+	results chan reviewResultMsg
+}
+
+// reviewResultMsg delivers one suggestion for the review list.
+type reviewResultMsg struct {
+	review *reviewState
+	item   int
+	value  string
+	err    error
+}
+
+// The review list's own keys.
+var (
+	reviewAcceptKey = key.NewBinding(key.WithKeys("y", "a"), key.WithHelp("y", "accept"))
+	reviewRejectKey = key.NewBinding(key.WithKeys("n", "r"), key.WithHelp("n", "reject"))
+	reviewEditKey   = key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit"))
+	reviewApplyKey  = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply accepted"))
+	reviewUpKey     = key.NewBinding(key.WithKeys("up", "k"))
+	reviewDownKey   = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// startReview requests suggestions for every empty placeholder from a
+// bounded pool of workers and opens the review list. The results channel
+// holds every answer, so workers never block once the review is gone.
+func (m *model) startReview() tea.Cmd {
+	r := &reviewState{}
+	for i, ph := range m.placeholders {
+		if ph.Resolved() == "" {
+			r.items = append(r.items, suggestion{index: i})
+		}
+	}
+	if len(r.items) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.pending = len(r.items)
+	r.results = make(chan reviewResultMsg, len(r.items))
+	m.review = r
+	slog.Debug("suggest all", "fields", len(r.items))
+
+	letter := m.filledText()
+	placeholders := append([]Placeholder(nil), m.placeholders...)
+	go func() {
+		sem := make(chan struct{}, suggestWorkers)
+		for n, s := range r.items {
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem }()
+				value, err := suggestValue(ctx, letter, placeholders[s.index])
+				r.results <- reviewResultMsg{review: r, item: n, value: value, err: err}
+			}()
+		}
+	}()
+	return waitForReview(r)
+}
+
+func waitForReview(r *reviewState) tea.Cmd {
+	return func() tea.Msg {
+		return <-r.results
+	}
+}
+
+// checkSuggestion holds s to its placeholder's type like a typed value,
+// resolving relative dates. One that doesn't fit is marked with the reason
+// and can't be accepted until it is edited.
+func (m model) checkSuggestion(s *suggestion) {
+	if s.err != nil || s.value == "" {
+		return
+	}
+	value, err := checkTyped(m.placeholders[s.index], s.value)
+	if err != nil {
+		s.err, s.accepted = err, false
+		return
+	}
+	s.value = value
+}
+
+// closeReview stops outstanding requests and leaves the review list.
+func (m *model) closeReview() {
+	m.review.cancel()
+	m.review = nil
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+}
+
+// updateReview handles keys while the review list is open.
+func (m *model) updateReview(msg tea.KeyMsg) tea.Cmd {
+	r := m.review
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+
+	if r.editing {
+		m.typeErr = ""
+		switch {
+		case key.Matches(msg, m.keys.Commit):
+			s := &r.items[r.cursor]
+			s.value, s.err = m.textInput.Value(), nil
+			s.accepted, s.rejected = s.value != "", false
+			m.checkSuggestion(s)
+			r.editing = false
+			m.textInput.Blur()
+		case key.Matches(msg, m.keys.Cancel):
+			r.editing = false
+			m.textInput.Blur()
+		default:
+			if m.typedKey(m.placeholders[r.items[r.cursor].index], msg) {
+				return nil
+			}
+			var cmd tea.Cmd
+			m.textInput, cmd = m.textInput.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	s := &r.items[r.cursor]
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		slog.Debug("suggest all cancelled", "pending", r.pending)
+		m.closeReview()
+	case key.Matches(msg, reviewApplyKey):
+		// Nothing is applied while an accepted value doesn't fit; the
+		// list stays open with the reason against it.
+		failed := false
+		for i := range r.items {
+			if s := &r.items[i]; s.accepted {
+				m.checkSuggestion(s)
+				failed = failed || s.err != nil
+			}
+		}
+		if failed {
+			return nil
+		}
+		applied := 0
+		for _, s := range r.items {
+			if s.accepted {
+				m.placeholders[s.index].Value = s.value
+				applied++
+			}
+		}
+		if applied > 0 {
+			m.saved = false
+		}
+		m.suggestionsUsed += applied
+		slog.Debug("suggestions applied", "count", applied)
+		m.closeReview()
+	case key.Matches(msg, reviewUpKey):
+		r.cursor = (r.cursor + len(r.items) - 1) % len(r.items)
+	case key.Matches(msg, reviewDownKey):
+		r.cursor = (r.cursor + 1) % len(r.items)
+	case key.Matches(msg, reviewAcceptKey):
+		if s.done && s.err == nil {
+			s.accepted, s.rejected = true, false
+		}
+	case key.Matches(msg, reviewRejectKey):
+		s.accepted, s.rejected = false, true
+	case key.Matches(msg, reviewEditKey):
+		ph := m.placeholders[s.index]
+		r.editing = true
+		m.textInput.CharLimit = defaultCharLimit
+		if ph.MaxLen > 0 {
+			m.textInput.CharLimit = ph.MaxLen
+		}
+		m.textInput.SetValue(s.value)
+		m.textInput.Placeholder = fmt.Sprintf("Enter %s", ph.Label())
+		return m.textInput.Focus()
+	}
+	return nil
+}
+
+// reviewView lists each empty field with its suggestion and whether it has
+// been accepted, in place of the letter.
+func (m model) reviewView() string {
+	r := m.review
+	ready := len(r.items) - r.pending
+	header := fmt.Sprintf("AI suggestions: %d/%d ready", ready, len(r.items))
+	if r.pending > 0 {
+		header += " • fetching…"
+	}
+	rows := []string{helpStyle.Render(header), ""}
+
+	width := m.viewport.Width
+	for i, s := range r.items {
+		marker := "  "
+		if i == r.cursor {
+			marker = activePlaceholderStyle.Render(cursorMark) + " "
+		}
+		var state, value string
+		switch {
+		case !s.done:
+			state, value = plain.Icon("⏳", "..."), helpStyle.Render("waiting…")
+		case s.err != nil:
+			state, value = errorStyle.Render("!"), errorStyle.Render(s.err.Error())
+		case s.accepted:
+			state, value = filledStyle.Render(filledMark), filledStyle.Render(s.value)
+		case s.rejected:
+			state, value = placeholderStyle.Render(emptyMark), helpStyle.Render(s.value)
+		default:
+			state, value = "•", s.value
+		}
+		label := m.placeholders[s.index].Label()
+		row := fmt.Sprintf("%s%s %s: %s", marker, state, label, value)
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width).Render(row))
+	}
+
+	if r.editing {
+		input := plain.Icon("✏️  ", "") + m.textInput.View()
+		if m.typeErr != "" {
+			input += " " + errorStyle.Render(m.typeErr)
+		}
+		rows = append(rows, "", inputBoxStyle.Render(input))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// reviewHelp is the footer while the review list is open.
+func (m model) reviewHelp() string {
+	if m.review.editing {
+		return helpText(m.keys.Commit, m.keys.Cancel)
+	}
+	return "↑↓ = move • " + helpText(reviewAcceptKey, reviewRejectKey, reviewEditKey, reviewApplyKey) +
+		" • " + keyLabel(m.keys.Cancel.Help().Key) + " = discard all"
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aign/render/config"
+	"aign/render/plain"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// saveSummaryConfigKey turns the screen shown after ctrl+s off, leaving
+// just the footer's note, with {"save_summary": false}.
+const saveSummaryConfigKey = "save_summary"
+
+// saveSummaryEnabled reports whether ctrl+s shows the summary screen.
+func saveSummaryEnabled() bool {
+	enabled := true
+	config.Value(saveSummaryConfigKey, &enabled)
+	return enabled
+}
+
+// summaryState is the screen shown after a save: what was written and
+// what to do next.
+type summaryState struct {
+	path   string
+	size   int64
+	format outputFormat
+	filled int
+	total  int
+	choice int
+}
+
+// summaryAction is one entry of the summary's menu.
+type summaryAction struct {
+	key   key.Binding
+	label string
+}
+
+// summaryActions are the summary's menu, in order.
+var summaryActions = []summaryAction{
+	{key.NewBinding(key.WithKeys("p")), "Preview the letter"},
+	{key.NewBinding(key.WithKeys("o")), "Open the folder"},
+	{key.NewBinding(key.WithKeys("c")), "Continue editing"},
+	{key.NewBinding(key.WithKeys("q")), "Quit"},
+}
+
+// Indexes into summaryActions.
+const (
+	summaryPreview = iota
+	summaryOpenFolder
+	summaryContinue
+	summaryQuit
+)
+
+// The summary's own keys.
+var (
+	summaryUpKey   = key.NewBinding(key.WithKeys("up", "k"))
+	summaryDownKey = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// openSummary shows the summary for the file just saved.
+func (m *model) openSummary() {
+	s := &summaryState{
+		path:   m.savedPath,
+		format: m.format,
+		filled: m.filledCount(),
+		total:  len(m.placeholders),
+		choice: summaryContinue,
+	}
+	if info, err := os.Stat(m.savedPath); err == nil {
+		s.size = info.Size()
+	} else {
+		slog.Warn("stat saved file", "path", m.savedPath, "err", err)
+	}
+	m.summary = s
+}
+
+// updateSummary handles keys while the summary is shown.
+func (m *model) updateSummary(msg tea.KeyMsg) tea.Cmd {
+	s := m.summary
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys.Cancel):
+		m.summary = nil
+		return nil
+	case key.Matches(msg, summaryUpKey):
+		s.choice = (s.choice + len(summaryActions) - 1) % len(summaryActions)
+		return nil
+	case key.Matches(msg, summaryDownKey):
+		s.choice = (s.choice + 1) % len(summaryActions)
+		return nil
+	case key.Matches(msg, m.keys.Commit):
+		return m.runSummaryAction(s.choice)
+	}
+	for i, a := range summaryActions {
+		if key.Matches(msg, a.key) {
+			return m.runSummaryAction(i)
+		}
+	}
+	return nil
+}
+
+// runSummaryAction closes the summary and does what entry i of the menu
+// says.
+func (m *model) runSummaryAction(i int) tea.Cmd {
+	path := m.summary.path
+	m.summary = nil
+	switch i {
+	case summaryPreview:
+		m.openFinal()
+	case summaryOpenFolder:
+		return openFile(filepath.Dir(path))
+	case summaryQuit:
+		return tea.Quit
+	}
+	return nil
+}
+
+// summaryView is the summary, for display in place of the letter.
+func (m model) summaryView() string {
+	s := m.summary
+	label := lipgloss.NewStyle().Width(12).Foreground(helpStyle.GetForeground())
+	completion := fmt.Sprintf("%d/%d placeholders filled", s.filled, s.total)
+	if s.filled < s.total {
+		completion = errorStyle.Render(completion)
+	} else {
+		completion = filledStyle.Render(completion)
+	}
+	rows := []string{
+		titleStyle.Render(plain.Icon("✅ ", "") + "Saved"),
+		"",
+		label.Render("File") + s.path,
+		label.Render("Size") + formatSize(s.size),
+		label.Render("Format") + string(s.format),
+		label.Render("Completion") + completion,
+		"",
+	}
+	for i, a := range summaryActions {
+		marker := "  "
+		if i == s.choice {
+			marker = activePlaceholderStyle.Render(cursorMark) + " "
+		}
+		rows = append(rows, fmt.Sprintf("%s%s  %s", marker, keyLabel(a.key.Keys()[0]), a.label))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// formatSize gives a file size in bytes, KB or MB.
+func formatSize(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d bytes", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
+
+// summaryHelp is the footer while the summary is shown.
+func (m model) summaryHelp() string {
+	return "↑↓ = move • enter = choose • " + keyLabel(m.keys.Cancel.Help().Key) + " = continue editing"
+}
@@ -0,0 +1,40 @@
+package main
+
+import "sort"
+
+// tabOrder lists placeholder indexes in the order tab visits them: those
+// that declare an order=, lowest first, then the rest as they appear in
+// the letter.
+func (m model) tabOrder() []int {
+	order := make([]int, len(m.placeholders))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		oa, ob := m.placeholders[order[a]].Order, m.placeholders[order[b]].Order
+		if oa == 0 || ob == 0 {
+			return oa != 0 && ob == 0
+		}
+		return oa < ob
+	})
+	return order
+}
+
+// tabStep is the placeholder n steps from the cursor along the tab order,
+// wrapping at either end. With no cursor, the first step forward lands on
+// the start of the order and the first step back on its end.
+func (m model) tabStep(n int) int {
+	order := m.tabOrder()
+	pos := -1
+	for p, i := range order {
+		if i == m.cursor {
+			pos = p
+			break
+		}
+	}
+	if pos == -1 && n < 0 {
+		pos = 0
+	}
+	pos = ((pos+n)%len(order) + len(order)) % len(order)
+	return order[pos]
+}
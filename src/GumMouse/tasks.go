@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"aign/render/plain"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// taskPattern matches the checkbox of a GitHub-style task list item, as in
+// "- [ ] Follow up" or "1. [x] Send CV", capturing its mark.
+var taskPattern = regexp.MustCompile(`(?m)^[ \t]*(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\]`)
+
+// taskBox is one checkbox in the letter.
+type taskBox struct {
+	offset  int // of the [ in the letter
+	checked bool
+}
+
+// taskBoxes finds the task list checkboxes in text, in order.
+func taskBoxes(text string) []taskBox {
+	var boxes []taskBox
+	for _, loc := range taskPattern.FindAllStringSubmatchIndex(text, -1) {
+		boxes = append(boxes, taskBox{
+			offset:  loc[2] - 1,
+			checked: text[loc[2]:loc[3]] != " ",
+		})
+	}
+	return boxes
+}
+
+// isTaskBox reports whether text[start:end] is a task list checkbox rather
+// than a placeholder, so [ ] and [x] after a list marker stay checkboxes.
+func isTaskBox(text string, start, end int) bool {
+	if end-start != 3 || text[start] != '[' {
+		return false
+	}
+	lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+	loc := taskPattern.FindStringIndex(text[lineStart:])
+	return loc != nil && lineStart+loc[1] == end
+}
+
+// taskZoneID is the bubblezone ID of the i-th checkbox.
+func taskZoneID(i int) string {
+	return fmt.Sprintf("task-%d", i)
+}
+
+// styleTask draws a checkbox the way the view shows it: as written in the
+// raw view, as a box otherwise.
+func (m model) styleTask(box taskBox) string {
+	styles := m.styles()
+	switch {
+	case m.raw && box.checked:
+		return styles.filled.Render("[x]")
+	case m.raw:
+		return styles.empty.Render("[ ]")
+	case box.checked:
+		return styles.filled.Render(plain.Icon("☑", "[x]"))
+	default:
+		return styles.empty.Render(plain.Icon("☐", "[ ]"))
+	}
+}
+
+// markTasks puts clickable tokens in place of the checkboxes in letter,
+// numbered from next, and returns the next free token number.
+func (m model) markTasks(letter string, next int, marks map[string]string) (string, int) {
+	boxes := taskBoxes(letter)
+	for i := len(boxes) - 1; i >= 0; i-- {
+		styled := m.styleTask(boxes[i])
+		token := placeholderToken(next+i, cellWidth(styled))
+		marks[token] = zone.Mark(taskZoneID(i), styled)
+		letter = letter[:boxes[i].offset] + token + letter[boxes[i].offset+3:]
+	}
+	return letter, next + len(boxes)
+}
+
+// toggleTask ticks or unticks the i-th checkbox in the letter.
+func (m *model) toggleTask(i int) {
+	boxes := taskBoxes(m.letterText)
+	if i < 0 || i >= len(boxes) {
+		return
+	}
+	mark := "x"
+	if boxes[i].checked {
+		mark = " "
+	}
+	at := boxes[i].offset + 1
+	m.letterText = m.letterText[:at] + mark + m.letterText[at+1:]
+	m.saved = false
+}
+
+// taskAt is the checkbox drawn under the screen cell x, y in the letter,
+// or -1.
+func (m model) taskAt(x, y int) int {
+	line, x, ok := m.viewportLine(x, y)
+	if !ok {
+		return -1
+	}
+	for i := range taskBoxes(m.letterText) {
+		if markedAt(line, x, taskZoneID(i)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The marks that tell filled placeholders from empty ones in the field
+// list and suggestion review. Themes that can't lean on colour alone also
+// set markInline, which puts them in the letter and the footer.
+var (
+	filledMark = "✓"
+	emptyMark  = "✗"
+	markInline = false
+)
+
+const (
+	themeDefault    = "default"
+	themeColorblind = "colorblind"
+)
+
+// applyTheme switches the placeholder styles to the named theme.
+func applyTheme(name string) error {
+	switch name {
+	case themeDefault:
+	case themeColorblind:
+		// Orange and sky blue from the Okabe-Ito palette stay apart under
+		// the common colour vision deficiencies, and the ○/● shapes carry
+		// the state even without them.
+		placeholderStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#E69F00")).
+			Background(lipgloss.Color("#3C3C3C")).
+			Bold(true)
+		activePlaceholderStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#F0E442")).
+			Bold(true)
+		bracketStyle = bracketStyle.Foreground(lipgloss.Color("#CC79A7"))
+		filledStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#56B4E9")).
+			Bold(true).
+			Underline(true)
+		filledMark, emptyMark, markInline = "●", "○", true
+	default:
+		return fmt.Errorf("unknown theme %q (want %s or %s)", name, themeDefault, themeColorblind)
+	}
+	return nil
+}
+
+// markFilled and markEmpty prefix a placeholder in the letter with its
+// state mark when the theme asks for it.
+func markFilled(s string) string {
+	if !markInline {
+		return s
+	}
+	return filledMark + " " + s
+}
+
+func markEmpty(s string) string {
+	if !markInline {
+		return s
+	}
+	return emptyMark + " " + s
+}
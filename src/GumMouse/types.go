@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The types a placeholder can be given, as in [StartDate:date]. Untyped
+// placeholders and those of a type the editor doesn't know are text.
+const (
+	typeText   = "text"
+	typeDate   = "date"
+	typeNumber = "number"
+	typeBool   = "bool"
+)
+
+// placeholderType is the type named t, or text when it isn't one.
+func placeholderType(t string) string {
+	switch t {
+	case typeText, typeDate, typeNumber, typeBool:
+		return t
+	}
+	slog.Debug("unknown placeholder type, using text", "type", t)
+	return typeText
+}
+
+// Bare is the placeholder as written without its type, which is how it is
+// shown and how it is saved while empty.
+func (ph Placeholder) Bare() string {
+	if ph.typeNote == "" {
+		return ph.Original
+	}
+	return strings.Replace(ph.Original, ph.typeNote, "", 1)
+}
+
+// dateLayouts are the ways a date can be typed into a date placeholder,
+// besides the relative forms defaults accept.
+var dateLayouts = []string{dateLayout, "Jan 2, 2006", "2006-01-02", "1/2/2006", "2 January 2006"}
+
+// checkTyped is value as it should be stored in ph: relative dates are
+// worked out, and a value that isn't of ph's type is an error.
+func checkTyped(ph Placeholder, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	switch ph.Type {
+	case typeDate:
+		if t, ok := parseRelativeDate(value, time.Now()); ok {
+			return t.Format(dateLayout), nil
+		}
+		for _, layout := range dateLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("not a date: try %s, 2006-01-02 or +2w", dateLayout)
+	case typeNumber:
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64); err != nil {
+			return "", fmt.Errorf("not a number")
+		}
+	}
+	return value, nil
+}
+
+// typedKey handles keys the input takes in its own way while it holds a
+// value for ph, reporting whether msg was one. Number placeholders refuse
+// letters; bool ones are a yes/no toggle rather than text.
+func (m *model) typedKey(ph Placeholder, msg tea.Msg) bool {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+	switch ph.Type {
+	case typeNumber:
+		if k.Type == tea.KeyRunes && !numeric(k.Runes) {
+			m.typeErr = "numbers only"
+			return true
+		}
+	case typeBool:
+		switch strings.ToLower(k.String()) {
+		case "y":
+			m.textInput.SetValue("Yes")
+		case "n":
+			m.textInput.SetValue("No")
+		case " ", "left", "right":
+			if m.textInput.Value() == "Yes" {
+				m.textInput.SetValue("No")
+			} else {
+				m.textInput.SetValue("Yes")
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// numeric reports whether runes could be part of a number.
+func numeric(runes []rune) bool {
+	for _, r := range runes {
+		if !strings.ContainsRune("0123456789.,-+", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// boolToggle draws a bool placeholder's input: Yes and No, with the one
+// chosen highlighted.
+func (m model) boolToggle() string {
+	yes, no := helpStyle.Render(" Yes "), helpStyle.Render(" No ")
+	switch m.textInput.Value() {
+	case "Yes":
+		yes = activePlaceholderStyle.Render(" Yes ")
+	case "No":
+		no = activePlaceholderStyle.Render(" No ")
+	}
+	return yes + " " + no + helpStyle.Render("  y/n or space to switch")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// occurrence is one place a placeholder is written in the letter.
+type occurrence struct {
+	offset    int // into the letter, in bytes
+	line, col int
+}
+
+// whereState is the list of places the placeholder at ph is used, shown
+// in place of the letter.
+type whereState struct {
+	ph    int
+	found []occurrence
+	index int
+}
+
+// The where-used list's own keys.
+var (
+	whereUpKey   = key.NewBinding(key.WithKeys("up", "k"))
+	whereDownKey = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// occurrences finds every place ph is written in the letter, leaving out
+// escaped text and comments, as substitution does.
+func (m model) occurrences(ph Placeholder) []occurrence {
+	text := m.syntax.mask(blankLines(m.letterText, isComment))
+	var found []occurrence
+	for i := 0; ; {
+		j := strings.Index(text[i:], ph.Original)
+		if j < 0 {
+			return found
+		}
+		line, col := position(text, i+j)
+		found = append(found, occurrence{i + j, line, col})
+		i += j + len(ph.Original)
+	}
+}
+
+// openWhere lists where the highlighted placeholder appears: the last one
+// edited or tabbed to, else the first.
+func (m *model) openWhere() {
+	if len(m.placeholders) == 0 {
+		return
+	}
+	i := max(m.cursor, 0)
+	m.where = &whereState{ph: i, found: m.occurrences(m.placeholders[i])}
+}
+
+// updateWhere handles keys while the where-used list is open. Enter
+// closes it with the letter scrolled to the selected place.
+func (m *model) updateWhere(msg tea.KeyMsg) tea.Cmd {
+	w := m.where
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys.Cancel, m.keys.Where, m.keys.Quit):
+		m.where = nil
+	case key.Matches(msg, whereDownKey):
+		if len(w.found) > 0 {
+			w.index = (w.index + 1) % len(w.found)
+		}
+	case key.Matches(msg, whereUpKey):
+		if len(w.found) > 0 {
+			w.index = (w.index + len(w.found) - 1) % len(w.found)
+		}
+	case key.Matches(msg, m.keys.Commit):
+		if line, ok := m.renderedLine(w.ph, w.index); ok {
+			m.viewport.SetYOffset(max(line-2, 0))
+		}
+		m.where = nil
+	}
+	return nil
+}
+
+// renderedLine is the line of the rendered letter holding occurrence n of
+// placeholder i, found by counting its zone markers: a start and an end
+// around the value, or around each word of a long one.
+func (m model) renderedLine(i, n int) (int, bool) {
+	ph := m.placeholders[i]
+	marker := zoneStart(ph.ID)
+	if marker == "" {
+		return 0, false
+	}
+	perUse := 2
+	if value := ph.Resolved(); cellWidth(value) > longValueWidth {
+		perUse = 2 * len(valueWords(markFilled(value), m.wrapWidth()))
+	}
+	offset := 0
+	for k := 0; k <= n*perUse; k++ {
+		j := strings.Index(m.content[offset:], marker)
+		if j < 0 {
+			return 0, false
+		}
+		offset += j
+		if k < n*perUse {
+			offset += len(marker)
+		}
+	}
+	return strings.Count(m.content[:offset], "\n"), true
+}
+
+// whereView lists each place the placeholder is used with its line, for
+// display in place of the letter.
+func (m model) whereView() string {
+	w := m.where
+	ph := m.placeholders[w.ph]
+	places := "places"
+	if len(w.found) == 1 {
+		places = "place"
+	}
+	rows := []string{helpStyle.Render(fmt.Sprintf("%s is used in %d %s", ph.Label(), len(w.found), places)), ""}
+	visible := max(m.viewport.Height-len(rows), 1)
+	start := min(max(w.index-visible/2, 0), max(len(w.found)-visible, 0))
+	end := min(start+visible, len(w.found))
+
+	for i := start; i < end; i++ {
+		o := w.found[i]
+		marker := "  "
+		if i == w.index {
+			marker = activePlaceholderStyle.Render(cursorMark) + " "
+		}
+		row := fmt.Sprintf("%sL%d:%d: %s", marker, o.line, o.col, m.occurrenceLine(o, ph))
+		rows = append(rows, ansi.Truncate(row, m.viewport.Width, "…"))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// occurrenceLine is the placeholder highlighted in a slice of its line.
+func (m model) occurrenceLine(o occurrence, ph Placeholder) string {
+	lineStart := strings.LastIndex(m.letterText[:o.offset], "\n") + 1
+	lineEnd := len(m.letterText)
+	if j := strings.Index(m.letterText[o.offset:], "\n"); j >= 0 {
+		lineEnd = o.offset + j
+	}
+	before := []rune(m.letterText[lineStart:o.offset])
+	after := []rune(m.letterText[o.offset+len(ph.Original) : lineEnd])
+
+	if len(before) > spellContext {
+		before = append([]rune("…"), before[len(before)-spellContext:]...)
+	}
+	if len(after) > spellContext {
+		after = append(after[:spellContext], '…')
+	}
+	return strings.TrimLeft(string(before), " \t") + activePlaceholderStyle.Render(ph.Original) + string(after)
+}
+
+// whereHelp is the footer while the where-used list is open.
+func (m model) whereHelp() string {
+	return "↑↓ = move • enter = jump to it • " + keyLabel(m.keys.Cancel.Help().Key) + " = back to letter"
+}
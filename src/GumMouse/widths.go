@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// cellWidth is how many terminal cells s takes, escapes aside, with wide
+// East Asian characters and emoji sequences counted as the terminal draws
+// them. Tokens are sized with it so glamour wraps around the real text.
+func cellWidth(s string) int {
+	return runewidth.StringWidth(ansi.Strip(s))
+}
+
+// placeholderAt is the placeholder drawn under the screen cell x, y in the
+// letter, or -1. bubblezone sums the widths of single runes, which puts
+// its bounds off for emoji built from several, so clicks on the letter
+// are matched against the zone markers here instead.
+func (m model) placeholderAt(x, y int) int {
+	line, x, ok := m.viewportLine(x, y)
+	if !ok {
+		return -1
+	}
+	for i, ph := range m.placeholders {
+		if markedAt(line, x, ph.ID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// viewportLine is the line of the letter drawn on screen row y, with x
+// made relative to the letter's left edge. ok is false off the letter.
+func (m model) viewportLine(x, y int) (line string, col int, ok bool) {
+	lines := strings.Split(m.viewport.View(), "\n")
+	row := y - m.viewportTop()
+	if row < 0 || row >= len(lines) {
+		return "", 0, false
+	}
+	if m.showSidebar {
+		x -= sidebarWidth
+	}
+	return lines[row], x, true
+}
+
+// markedAt reports whether cell x of line falls inside the zone id.
+func markedAt(line string, x int, id string) bool {
+	marker := zoneStart(id)
+	if marker == "" {
+		return false
+	}
+	// Markers come in pairs around each stretch of the value.
+	for from := 0; ; {
+		start := strings.Index(line[from:], marker)
+		if start < 0 {
+			return false
+		}
+		start += from
+		end := strings.Index(line[start+len(marker):], marker)
+		if end < 0 {
+			end = len(line)
+		} else {
+			end += start + len(marker)
+		}
+		left := cellWidth(line[:start])
+		if x >= left && x < left+cellWidth(line[start:end]) {
+			return true
+		}
+		from = min(end+len(marker), len(line))
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"aign/render/config"
+	"aign/render/plain"
+)
+
+// wordLimitConfigKey sets the word count the footer warns above, e.g.
+// "word_limit": 350. Zero turns the warning off.
+const (
+	wordLimitConfigKey = "word_limit"
+	defaultWordLimit   = 400
+)
+
+// loadWordLimit reads the word limit from config.
+func loadWordLimit() int {
+	limit := defaultWordLimit
+	config.Value(wordLimitConfigKey, &limit)
+	return limit
+}
+
+var (
+	// mdLink is an inline link or image; only its text counts.
+	mdLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	// mdLineMarker is a heading, quote or list marker opening a line, or a
+	// line that is only a rule.
+	mdLineMarker = regexp.MustCompile(`(?m)^[ \t]*(?:(?:#{1,6}|>|[-*+]|\d+[.)])[ \t]+|(?:[-*_][ \t]*){3,}$)`)
+	// mdEmphasis is a run of emphasis, code or strikethrough marks, with
+	// underscores only where they aren't inside a word.
+	mdEmphasis = regexp.MustCompile("[*`~]+|\\b_+\\B|\\B_+\\b")
+)
+
+// countLetter counts the words and characters of the letter as saved,
+// leaving out markdown syntax. Characters include spaces but not line
+// breaks.
+func countLetter(md string) (words, chars int) {
+	text := mdLink.ReplaceAllString(md, "$1")
+	text = mdLineMarker.ReplaceAllString(text, "")
+	text = mdEmphasis.ReplaceAllString(text, "")
+	for _, line := range strings.Split(text, "\n") {
+		chars += utf8.RuneCountInString(strings.TrimSpace(line))
+	}
+	return len(strings.Fields(text)), chars
+}
+
+// countView is the footer's word and character count, the words in the
+// warning colour past the word limit.
+func (m model) countView() string {
+	words, chars := countLetter(m.filledText())
+	if m.wordLimit > 0 && words > m.wordLimit {
+		return wordLimitStyle.Render(fmt.Sprintf("%d/%d words", words, m.wordLimit)+plain.Icon("", " (over limit)")) +
+			helpStyle.Render(fmt.Sprintf(", %d chars", chars))
+	}
+	return helpStyle.Render(fmt.Sprintf("%d words, %d chars", words, chars))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCountLetter(t *testing.T) {
+	tests := []struct {
+		in           string
+		words, chars int
+	}{
+		{"Dear Acme,\n\nI apply.", 4, 18},
+		{"# Heading\n\n**Bold** and *it* and `code`", 6, 27},
+		{"- one\n- two\n1. three\n> quoted", 4, 17},
+		{"See [my site](https://example.com).\n\n---\n", 3, 12},
+		{"snake_case and __strong__ words", 4, 27},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		if words, chars := countLetter(tt.in); words != tt.words || chars != tt.chars {
+			t.Errorf("countLetter(%q) = %d words, %d chars, want %d, %d", tt.in, words, chars, tt.words, tt.chars)
+		}
+	}
+}
+
+func TestEditorWordCount(t *testing.T) {
+	m := initialModel(writeLetter(t, "Dear **[Company]** team,\n"), "brackets")
+	m.wordLimit = 3
+	m.ready = true
+	if got := ansi.Strip(m.View()); !strings.Contains(got, "3 words, 20 chars") {
+		t.Errorf("footer doesn't count the letter:\n%s", got)
+	}
+	m.placeholders[0].Value = "Big Corp"
+	if got := ansi.Strip(m.countView()); got != "4/3 words, 19 chars" {
+		t.Errorf("count over the limit = %q", got)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wrapMark starts each line that -show-wraps finds is the wrapped-over end
+// of the line above rather than a line of its own.
+const wrapMark = "↪"
+
+var wrapMarkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+// leadingSGR matches the colour escapes before a rendered line's first
+// column.
+var leadingSGR = regexp.MustCompile(`^(?:\x1b\[[0-9;]*m)*`)
+
+// markWraps marks the lines of wrapped that continue the line before
+// them. unwrapped is the same content laid out without wrapping: reading
+// both with whitespace ignored, a wrapped line that carries on a line of
+// unwrapped that is not yet finished is a continuation. The mark takes the
+// place of the line's leading space, so nothing after it moves; a line
+// without one is left as it is.
+func markWraps(wrapped, unwrapped string) string {
+	var logical []string
+	for _, line := range strings.Split(unwrapped, "\n") {
+		if text := squash(line); text != "" {
+			logical = append(logical, text)
+		}
+	}
+
+	lines := strings.Split(wrapped, "\n")
+	next, rest := 0, "" // rest is what is still to come of the current line
+	for i, line := range lines {
+		text := squash(line)
+		if text == "" {
+			continue
+		}
+		if rest != "" {
+			switch {
+			case strings.HasPrefix(rest, text):
+				lines[i] = markLine(line)
+				rest = rest[len(text):]
+				continue
+			case strings.HasPrefix(text, rest):
+				lines[i] = markLine(line)
+				text = text[len(rest):]
+			}
+			rest = ""
+		}
+
+		// Whatever is left starts one or more lines of its own; glamour
+		// joins a paragraph's lines before wrapping it.
+		next = resync(logical, next, text)
+		for text != "" && next < len(logical) {
+			if strings.HasPrefix(text, logical[next]) {
+				text = text[len(logical[next]):]
+				next++
+				continue
+			}
+			if strings.HasPrefix(logical[next], text) {
+				rest = logical[next][len(text):]
+				next++
+			}
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resync finds the first line from next on that text could begin, so one
+// line that doesn't match up doesn't throw off the rest.
+func resync(logical []string, next int, text string) int {
+	for j := next; j < len(logical); j++ {
+		if strings.HasPrefix(text, logical[j]) || strings.HasPrefix(logical[j], text) {
+			return j
+		}
+	}
+	return next
+}
+
+// squash is a line's text with styling and whitespace removed, so lines
+// can be compared however they were wrapped.
+func squash(line string) string {
+	return strings.Join(strings.Fields(ansi.Strip(line)), "")
+}
+
+// markLine replaces the first column of line with wrapMark if it is blank.
+func markLine(line string) string {
+	n := len(leadingSGR.FindString(line))
+	if !strings.HasPrefix(line[n:], " ") {
+		return line
+	}
+	return line[:n] + wrapMarkStyle.Render(wrapMark) + line[n+1:]
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiPattern matches CSI sequences (colors, cursor movement) and OSC
+// sequences (hyperlinks, titles) terminated by BEL or ST.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// ansiToken stands in for each escape sequence while glamour renders. It is
+// a private-use rune, so glamour passes it through untouched and in order,
+// but it still counts as one column when lines are word-wrapped: with
+// -raw-ansi, heavily colored lines wrap slightly earlier than plain ones.
+const ansiToken = "\uE000"
+
+// extractANSI replaces every escape sequence in s with ansiToken and
+// returns the sequences in the order they appeared.
+func extractANSI(s string) (string, []string) {
+	seqs := ansiPattern.FindAllString(s, -1)
+	return ansiPattern.ReplaceAllLiteralString(s, ansiToken), seqs
+}
+
+// restoreANSI puts seqs back in place of the tokens left by extractANSI.
+func restoreANSI(s string, seqs []string) string {
+	return replaceTokens(s, ansiToken, seqs)
+}
+
+// replaceTokens replaces the n-th occurrence of token in s with repl[n].
+// Occurrences beyond len(repl) are dropped.
+func replaceTokens(s, token string, repl []string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(s, token) {
+		if i > 0 && i-1 < len(repl) {
+			b.WriteString(repl[i-1])
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// imagePattern matches ![alt](path) and ![alt](path "title").
+var imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+
+// imageToken marks where an inline image goes in the rendered output.
+const imageToken = "\uE002"
+
+// imageCells is the width, in terminal columns, images are drawn at.
+const imageCells = 40
+
+type imageProtocol int
+
+const (
+	imagesNone imageProtocol = iota
+	imagesKitty
+	imagesITerm
+)
+
+// detectImageProtocol guesses the terminal's graphics protocol from the
+// environment variables the terminals themselves set.
+func detectImageProtocol() imageProtocol {
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("TERM") == "xterm-kitty":
+		return imagesKitty
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return imagesITerm
+	}
+	return imagesNone
+}
+
+// extractImages replaces each local image reference that can be drawn with
+// imageToken and returns the escape sequences to draw them, in order.
+// Remote or unreadable images are left for glamour to render as alt text.
+func extractImages(md, baseDir string, proto imageProtocol) (string, []string) {
+	var seqs []string
+	out := imagePattern.ReplaceAllStringFunc(md, func(ref string) string {
+		target := imagePattern.FindStringSubmatch(ref)[2]
+		if strings.Contains(target, "://") {
+			return ref
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(baseDir, target)
+		}
+		// Kitty's direct transmission only understands PNG without decoding.
+		if proto == imagesKitty && !strings.EqualFold(filepath.Ext(target), ".png") {
+			return ref
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return ref
+		}
+		seqs = append(seqs, imageSequence(proto, data))
+		return imageToken
+	})
+	return out, seqs
+}
+
+func imageSequence(proto imageProtocol, data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	if proto == imagesITerm {
+		return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;preserveAspectRatio=1:%s\a",
+			len(data), imageCells, encoded)
+	}
+
+	// Kitty payloads are sent in chunks of at most 4096 bytes, each flagged
+	// with whether more follow.
+	var b strings.Builder
+	for i := 0; i < len(encoded); i += 4096 {
+		end := min(i+4096, len(encoded))
+		more := 0
+		if end < len(encoded) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Gf=100,a=T,c=%d,m=%d;%s\x1b\\", imageCells, more, encoded[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, encoded[i:end])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// linkWorkers bounds how many HTTP links are checked at once.
+const linkWorkers = 4
+
+// linkResult is the outcome of checking one link target.
+type linkResult struct {
+	target string
+	// problem is empty when the link works, and skipped set when it was
+	// not checked at all.
+	problem string
+	skipped bool
+}
+
+// extractLinks returns the distinct targets of links, autolinks and images
+// in md, in document order.
+func extractLinks(md string) []string {
+	src := []byte(md)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(t string) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			targets = append(targets, t)
+		}
+	}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			add(string(n.Destination))
+		case *ast.Image:
+			add(string(n.Destination))
+		case *ast.AutoLink:
+			if n.AutoLinkType == ast.AutoLinkURL {
+				add(string(n.URL(src)))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return targets
+}
+
+// checkLinks checks every target: local paths must exist relative to
+// baseDir, and HTTP(S) URLs must answer a HEAD request without an error
+// status unless offline is set. Other schemes and in-page anchors are
+// skipped.
+func checkLinks(targets []string, baseDir string, timeout time.Duration, offline bool) []linkResult {
+	results := make([]linkResult, len(targets))
+	client := &http.Client{Timeout: timeout}
+	sem := make(chan struct{}, linkWorkers)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		results[i].target = target
+		u, err := url.Parse(target)
+		switch {
+		case err != nil:
+			results[i].problem = "malformed link"
+		case u.Scheme == "http" || u.Scheme == "https":
+			if offline {
+				results[i].skipped = true
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i].problem = checkURL(client, target)
+			}()
+		case u.Scheme != "" || u.Path == "":
+			// mailto:, tel: and #anchors have nothing to look up.
+			results[i].skipped = true
+		default:
+			results[i].problem = checkFile(u.Path, baseDir)
+		}
+	}
+	wg.Wait()
+	return results
+}
+
+func checkFile(path, baseDir string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "missing file"
+		}
+		return err.Error()
+	}
+	return ""
+}
+
+// checkURL asks for target's headers, falling back to GET for servers that
+// refuse HEAD.
+func checkURL(client *http.Client, target string) string {
+	status, err := request(client, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = request(client, http.MethodGet, target)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if status >= 400 {
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return ""
+}
+
+func request(client *http.Client, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// reportLinks writes the broken links and a one-line tally to w, and
+// reports whether every checked link worked.
+func reportLinks(w io.Writer, results []linkResult) bool {
+	var ok, broken, skipped int
+	for _, r := range results {
+		switch {
+		case r.skipped:
+			skipped++
+		case r.problem != "":
+			broken++
+			fmt.Fprintf(w, "broken link: %s (%s)\n", r.target, r.problem)
+		default:
+			ok++
+		}
+	}
+	summary := fmt.Sprintf("links: %d ok, %d broken", ok, broken)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	fmt.Fprintln(w, summary)
+	return broken == 0
+}
@@ -1,61 +1,137 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/charmbracelet/glamour"
+	"aign/render"
+	"aign/render/logging"
+	"aign/render/profiling"
 	"github.com/charmbracelet/glamour/styles"
 	"github.com/muesli/termenv"
 )
 
 func main() {
+	rawANSI := flag.Bool("raw-ansi", false, "Keep ANSI escape sequences found in the input instead of stripping them (each counts as one column when wrapping)")
+	images := flag.Bool("images", false, "Draw local images inline on kitty/iTerm2-compatible terminals")
+	checkLinksFlag := flag.Bool("check-links", false, "After rendering, report broken links on stderr and exit non-zero if any")
+	timeout := flag.Duration("timeout", 5*time.Second, "Time allowed for each HTTP link check")
+	offline := flag.Bool("offline", false, "With -check-links, only check local file links")
+	width := flag.Int("width", 0, "Column to word-wrap at (default: the terminal width, kept within 40-120, or 80 when output isn't a terminal)")
+	theme := flag.String("theme", styles.DarkStyle, "Style to render with: "+strings.Join(themes, ", "))
+	pager := flag.Bool("pager", false, "View the output in a scrollable pager that re-wraps to the terminal width")
+	verbose := flag.Bool("verbose", false, "Write a debug log to $XDG_STATE_HOME/aign/aign.log")
+	pprofPrefix := flag.String(profiling.Flag, "", "Write CPU and heap profiles of rendering to `prefix`.cpu and prefix.mem")
+	profiling.HideFlag()
+	flag.Parse()
+	if !validTheme(*theme) {
+		fmt.Fprintf(os.Stderr, "Unknown theme %q; choose one of: %s\n", *theme, strings.Join(themes, ", "))
+		os.Exit(2)
+	}
+
+	closeLog, err := logging.Setup("glamour", *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging disabled: %v\n", err)
+	}
+	defer closeLog()
+
 	var content []byte
-	var err error
+	baseDir := "."
 
-	if len(os.Args) < 2 {
+	if flag.NArg() < 1 {
 		// Try reading from stdin
 		stat, _ := os.Stdin.Stat()
 		if (stat.Mode() & os.ModeCharDevice) == 0 {
 			content, err = io.ReadAll(os.Stdin)
+			slog.Debug("read stdin", "bytes", len(content), "err", err)
 			if err != nil {
 				log.Fatalf("Error reading from stdin: %v", err)
 			}
 		} else {
-			fmt.Println("Usage: go run main.go <markdown-file> or pipe markdown to stdin")
+			fmt.Println("Usage: go run . [-raw-ansi] [-images] [-check-links] [-pager] [-width N] <markdown-file> or pipe markdown to stdin")
 			os.Exit(1)
 		}
 	} else {
-		filePath := os.Args[1]
+		filePath := flag.Arg(0)
+		baseDir = filepath.Dir(filePath)
 		content, err = os.ReadFile(filePath)
+		slog.Debug("read file", "path", filePath, "bytes", len(content), "err", err)
 		if err != nil {
 			log.Fatalf("Error reading file: %v", err)
 		}
 	}
 
-	// Create a custom style based on the dark theme but without prefixes
-	style := styles.DarkStyleConfig
-	style.H1.Prefix = ""
-	style.H1.Suffix = ""
-	style.H2.Prefix = ""
-	style.H2.Suffix = ""
+	// Escape sequences from an upstream colorizer confuse the markdown
+	// parser, so they are always lifted out before rendering.
+	markdown, seqs := extractANSI(string(content))
+	source := markdown
 
-	// Create a new renderer with the specific content style
-	r, err := glamour.NewTermRenderer(
-		glamour.WithStyles(style),
-		glamour.WithWordWrap(80),
-		glamour.WithColorProfile(termenv.TrueColor),
-	)
-	if err != nil {
-		log.Fatalf("Error initializing renderer: %v", err)
+	// Image references become tokens that are swapped for inline graphics
+	// after rendering; elsewhere glamour falls back to the alt text.
+	var pictures []string
+	if proto := detectImageProtocol(); *images && proto != imagesNone {
+		markdown, pictures = extractImages(markdown, baseDir, proto)
+		slog.Debug("extracted images", "protocol", proto, "count", len(pictures))
+	}
+
+	// renderAt word-wraps the markdown at width. The pager calls it again
+	// whenever the terminal is resized.
+	renderAt := func(width int) (string, error) {
+		// The chosen style without heading markers, as the editor shows it
+		r, err := render.New(render.Options{Style: *theme, Width: width, ColorProfile: termenv.TrueColor})
+		if err != nil {
+			return "", fmt.Errorf("initializing renderer: %w", err)
+		}
+
+		out, err := r.Render(markdown)
+		if err != nil {
+			return "", fmt.Errorf("rendering markdown: %w", err)
+		}
+
+		if *rawANSI {
+			out = restoreANSI(out, seqs)
+		} else {
+			out = strings.ReplaceAll(out, ansiToken, "")
+		}
+		out = replaceTokens(out, imageToken, pictures)
+
+		slog.Debug("rendered", "width", width, "ansi_sequences", len(seqs), "raw_ansi", *rawANSI, "bytes", len(out))
+		return out, nil
 	}
 
-	out, err := r.Render(string(content))
+	stopProfiling, err := profiling.Start(*pprofPrefix)
 	if err != nil {
-		log.Fatalf("Error rendering markdown: %v", err)
+		log.Fatalf("Error starting profile: %v", err)
+	}
+	if *pager {
+		err = runPager(renderAt)
+	} else {
+		var out string
+		if out, err = renderAt(wrapWidth(*width)); err == nil {
+			fmt.Print(out)
+		}
+	}
+	if perr := stopProfiling(); perr != nil {
+		slog.Error("write profile", "err", perr)
+		fmt.Fprintf(os.Stderr, "Warning: profile incomplete: %v\n", perr)
+	}
+	if err != nil {
+		slog.Error("render failed", "pager", *pager, "err", err)
+		log.Fatalf("Error: %v", err)
 	}
 
-	fmt.Print(out)
+	if *checkLinksFlag {
+		results := checkLinks(extractLinks(source), baseDir, *timeout, *offline)
+		slog.Debug("checked links", "count", len(results))
+		if !reportLinks(os.Stderr, results) {
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"aign/render/terminal"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// resizeDebounce is how long the terminal size must hold still before the
+// markdown is re-wrapped, so dragging a window edge renders once.
+const resizeDebounce = 100 * time.Millisecond
+
+var pagerHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// resizeTickMsg fires after resizeDebounce; it carries the resize sequence
+// number it was scheduled for so stale ticks can be ignored.
+type resizeTickMsg int
+
+// pagerModel shows rendered markdown in a viewport and re-renders it at
+// the terminal's width when that changes.
+type pagerModel struct {
+	viewport viewport.Model
+	render   func(width int) (string, error)
+	width    int // width the content was last rendered at
+	ready    bool
+	seq      int
+	err      error
+}
+
+// runPager renders through render and pages the result until q is pressed.
+// Keys come from the terminal, so markdown piped on stdin still works.
+func runPager(render func(width int) (string, error)) error {
+	defer terminal.RestoreOnPanic(os.Stdout)
+
+	p := tea.NewProgram(pagerModel{render: render}, tea.WithAltScreen(), tea.WithInputTTY())
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	return final.(pagerModel).err
+}
+
+func (m pagerModel) Init() tea.Cmd { return nil }
+
+func (m pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+
+	case tea.WindowSizeMsg:
+		height := msg.Height - 1
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.ready = true
+			return m, m.rerender()
+		}
+		m.viewport.Width = msg.Width
+		m.viewport.Height = height
+		m.seq++
+		seq := m.seq
+		return m, tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+			return resizeTickMsg(seq)
+		})
+
+	case resizeTickMsg:
+		if int(msg) != m.seq || m.viewport.Width == m.width {
+			return m, nil
+		}
+		return m, m.rerender()
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// rerender wraps the markdown at the viewport's width, keeping the reader
+// at the same fraction of the document.
+func (m *pagerModel) rerender() tea.Cmd {
+	out, err := m.render(m.viewport.Width)
+	if err != nil {
+		m.err = err
+		return tea.Quit
+	}
+	percent := m.viewport.ScrollPercent()
+	m.viewport.SetContent(out)
+	m.viewport.SetYOffset(int(percent * float64(max(0, m.viewport.TotalLineCount()-m.viewport.Height))))
+	slog.Debug("pager rerendered", "from", m.width, "to", m.viewport.Width)
+	m.width = m.viewport.Width
+	return nil
+}
+
+func (m pagerModel) View() string {
+	if !m.ready {
+		return ""
+	}
+	footer := fmt.Sprintf("%3.f%% • ↑↓/pgup/pgdn = scroll • q = quit", m.viewport.ScrollPercent()*100)
+	return m.viewport.View() + "\n" + pagerHelpStyle.Render(footer)
+}
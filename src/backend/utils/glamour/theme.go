@@ -0,0 +1,23 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/glamour/styles"
+)
+
+// themes are the built-in glamour styles -theme accepts. The render
+// package strips the heading markers from whichever one is chosen.
+var themes = []string{
+	styles.DarkStyle,
+	styles.LightStyle,
+	styles.DraculaStyle,
+	styles.NoTTYStyle,
+	styles.AsciiStyle,
+	styles.PinkStyle,
+}
+
+// validTheme reports whether name is one of themes.
+func validTheme(name string) bool {
+	return slices.Contains(themes, name)
+}
@@ -0,0 +1,14 @@
+package main
+
+import "testing"
+
+func TestValidTheme(t *testing.T) {
+	for _, name := range themes {
+		if !validTheme(name) {
+			t.Errorf("validTheme(%q) = false for a listed theme", name)
+		}
+	}
+	if validTheme("solarized") {
+		t.Error(`validTheme("solarized") = true for an unknown theme`)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Word-wrap columns used when -width isn't given. Detected widths are
+// kept between minWidth and maxWidth so very wide terminals don't give
+// unreadably long lines and narrow ones still fit a sentence.
+const (
+	defaultWidth = 80
+	minWidth     = 40
+	maxWidth     = 120
+)
+
+// wrapWidth returns the column to word-wrap at: the -width flag when set,
+// otherwise the width of the terminal on stdout. Output that isn't going
+// to a terminal keeps defaultWidth so redirected renders don't depend on
+// the window they were run from.
+func wrapWidth(flagWidth int) int {
+	if flagWidth > 0 {
+		return flagWidth
+	}
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return defaultWidth
+	}
+	w, _, err := term.GetSize(fd)
+	if err != nil || w <= 0 {
+		return defaultWidth
+	}
+	return clampWidth(w)
+}
+
+// clampWidth keeps a detected terminal width within [minWidth, maxWidth].
+func clampWidth(w int) int {
+	return min(max(w, minWidth), maxWidth)
+}
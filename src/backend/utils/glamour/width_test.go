@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/term"
+)
+
+func TestWrapWidth(t *testing.T) {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		t.Skip("stdout is a terminal; the default width only applies to redirected output")
+	}
+	for _, tt := range []struct {
+		name string
+		flag int
+		want int
+	}{
+		{"default when not a terminal", 0, defaultWidth},
+		{"negative flag is unset", -5, defaultWidth},
+		{"flag overrides", 100, 100},
+		{"flag isn't clamped low", 20, 20},
+		{"flag isn't clamped high", 200, 200},
+	} {
+		if got := wrapWidth(tt.flag); got != tt.want {
+			t.Errorf("%s: wrapWidth(%d) = %d, want %d", tt.name, tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestClampWidth(t *testing.T) {
+	for _, tt := range []struct {
+		in, want int
+	}{
+		{1, minWidth},
+		{39, minWidth},
+		{40, 40},
+		{80, 80},
+		{120, 120},
+		{121, maxWidth},
+		{300, maxWidth},
+	} {
+		if got := clampWidth(tt.in); got != tt.want {
+			t.Errorf("clampWidth(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
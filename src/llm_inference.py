@@ -26,6 +26,42 @@ def strip_think_blocks(text: str) -> str:
     text = re.sub(r"<analysis>.*?</analysis>\s*", "", text, flags=re.DOTALL | re.IGNORECASE)
     return text.strip()
 
+def visible_so_far(text: str) -> str:
+    """
+    The part of a reply still being generated that can be shown: closed
+    <think> / <analysis> blocks removed, and nothing from one still open
+    or from a tag that may be only partly written.
+    """
+    text = re.sub(r"<think>.*?</think>\s*", "", text, flags=re.DOTALL | re.IGNORECASE)
+    text = re.sub(r"<analysis>.*?</analysis>\s*", "", text, flags=re.DOTALL | re.IGNORECASE)
+    opened = re.search(r"<(think|analysis)>", text, flags=re.IGNORECASE)
+    if opened:
+        text = text[:opened.start()]
+    lt = text.rfind("<")
+    if lt != -1 and ">" not in text[lt:]:
+        text = text[:lt]
+    return text.lstrip()
+
+def stream_reply(llm, messages) -> None:
+    """
+    Print the reply as the model writes it, flushing each piece so a
+    program reading the pipe (the editor's ctrl+t) shows it arriving.
+    Think blocks are dropped as strip_think_blocks does.
+    """
+    raw, shown = "", ""
+    for chunk in llm.create_chat_completion(messages=messages, stream=True):
+        raw += chunk["choices"][0]["delta"].get("content") or ""
+        # Trailing whitespace waits for what follows, as the final
+        # strip may drop it.
+        visible = visible_so_far(raw).rstrip()
+        if len(visible) > len(shown) and visible.startswith(shown):
+            print(visible[len(shown):], end="", flush=True)
+            shown = visible
+    final = strip_think_blocks(raw)
+    if final.startswith(shown):
+        print(final[len(shown):], end="")
+    print(flush=True)
+
 def read_chat_input() -> str:
     """
     Claude Code-style input: intercepts large pastes and shows a placeholder.
@@ -110,20 +146,11 @@ def main():
             if user_text.lower() in ["/exit", "/quit"]:
                 break
 
-            output = llm.create_chat_completion(
-                messages=base_messages + [{"role": "user", "content": user_text}]
-            )
-            raw = output["choices"][0]["message"]["content"]
-
-            print(strip_think_blocks(raw))
+            stream_reply(llm, base_messages + [{"role": "user", "content": user_text}])
     else:
         # Single-shot mode
         user_text = "What is the capital of France?"
-        output = llm.create_chat_completion(
-            messages=base_messages + [{"role": "user", "content": user_text}]
-        )
-        raw = output["choices"][0]["message"]["content"]
-        print(strip_think_blocks(raw))
+        stream_reply(llm, base_messages + [{"role": "user", "content": user_text}])
 
 if __name__ == "__main__":
     main()
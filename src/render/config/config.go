@@ -0,0 +1,140 @@
+// Package config reads and writes the settings file shared by the aign
+// tools, including the "keys" section where each tool's key bindings can
+// be remapped. Each tool keeps its own settings and key map; this package
+// only knows how they are stored.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeysKey is the section holding remapped keys, by tool and then by
+// action name, e.g. {"editor": {"save": ["ctrl+s", "ctrl+w"]}}.
+const KeysKey = "keys"
+
+// Path is the settings file: $XDG_CONFIG_HOME/aign/config.json, or
+// ~/.config/aign/config.json.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "aign", "config.json"), nil
+}
+
+// Load reads the config file as raw values keyed by setting name, so each
+// tool can update its own keys without dropping the others'. A missing
+// file is an empty config.
+func Load() (map[string]json.RawMessage, error) {
+	cfg := make(map[string]json.RawMessage)
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, json.Unmarshal(data, &cfg)
+}
+
+// Value decodes the setting key into v, reporting whether it was set.
+func Value(key string, v any) bool {
+	cfg, err := Load()
+	if err != nil {
+		return false
+	}
+	raw, ok := cfg[key]
+	return ok && json.Unmarshal(raw, v) == nil
+}
+
+// SetValue stores v under key, keeping the rest of the file as is.
+func SetValue(key string, v any) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	cfg[key] = raw
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadKeys applies tool's section of the key settings to actions, a key
+// map's bindings by the names they have in config. Unknown actions, empty
+// bindings and a key bound to two actions are errors.
+func LoadKeys(tool string, actions map[string]*key.Binding) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	var sections map[string]map[string][]string
+	if raw, ok := cfg[KeysKey]; ok {
+		if err := json.Unmarshal(raw, &sections); err != nil {
+			return fmt.Errorf("keys: %w", err)
+		}
+	}
+
+	for name, keys := range sections[tool] {
+		b, ok := actions[name]
+		if !ok {
+			return fmt.Errorf("keys.%s: unknown action %q", tool, name)
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("keys.%s.%s: no keys given", tool, name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	return checkConflicts(actions)
+}
+
+// checkConflicts reports the first key bound to more than one action.
+func checkConflicts(actions map[string]*key.Binding) error {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	owner := make(map[string]string)
+	for _, name := range names {
+		for _, k := range actions[name].Keys() {
+			if other, ok := owner[k]; ok {
+				return fmt.Errorf("key %q is bound to both %s and %s", k, other, name)
+			}
+			owner[k] = name
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+func TestValue(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var n int
+	if Value("split", &n) {
+		t.Error("Value found a setting in a missing file")
+	}
+	if err := SetValue("split", 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetValue("theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	var theme string
+	if !Value("split", &n) || n != 40 || !Value("theme", &theme) || theme != "dark" {
+		t.Errorf("split = %d, theme = %q; want 40 and dark", n, theme)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys map[string]map[string][]string
+		err  string
+	}{
+		{"remap", map[string]map[string][]string{"editor": {"save": {"ctrl+w"}}, "picker": {"quit": {"x"}}}, ""},
+		{"unknown action", map[string]map[string][]string{"editor": {"fly": {"f"}}}, `keys.editor: unknown action "fly"`},
+		{"no keys", map[string]map[string][]string{"editor": {"save": {}}}, "keys.editor.save: no keys given"},
+		{"conflict", map[string]map[string][]string{"editor": {"save": {"q"}}}, `key "q" is bound to both quit and save`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if err := SetValue(KeysKey, tt.keys); err != nil {
+				t.Fatal(err)
+			}
+			quit := key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit"))
+			save := key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save"))
+
+			err := LoadKeys("editor", map[string]*key.Binding{"quit": &quit, "save": &save})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := save.Keys(); len(got) != 1 || got[0] != "ctrl+w" || save.Help().Key != "ctrl+w" {
+				t.Errorf("save = %q (help %q), want ctrl+w", got, save.Help().Key)
+			}
+			if got := quit.Keys(); len(got) != 1 || got[0] != "q" {
+				t.Errorf("quit = %q, another tool's remap leaked in", got)
+			}
+		})
+	}
+}
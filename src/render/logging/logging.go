@@ -0,0 +1,59 @@
+// Package logging is the -verbose file logging shared by the cover letter
+// editor, the file picker and the standalone markdown renderer. All three
+// draw on the terminal or print their result on stdout, so nothing is
+// ever logged to stdout or stderr.
+package logging
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Path is where -verbose writes: $XDG_STATE_HOME/aign/aign.log, or
+// ~/.local/state/aign/aign.log.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "aign", "aign.log"), nil
+}
+
+// Setup sends slog records to the log file, tagged with tool, when
+// verbose is set and drops them otherwise. The returned func closes the
+// file.
+func Setup(tool string, verbose bool) (func(), error) {
+	// slog.SetDefault reroutes the log package as well, but its fatal
+	// errors are for the user and still belong on stderr.
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	if !verbose {
+		return func() {}, nil
+	}
+
+	path, err := Path()
+	if err != nil {
+		return func() {}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return func() {}, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return func() {}, err
+	}
+
+	h := slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})
+	slog.SetDefault(slog.New(h).With("tool", tool, "pid", os.Getpid()))
+	return func() { f.Close() }, nil
+}
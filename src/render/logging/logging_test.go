@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	saved := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(saved) })
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closeLog, err := Setup("test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("dropped")
+	closeLog()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("log written without verbose: %v", err)
+	}
+
+	closeLog, err = Setup("test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("kept")
+	closeLog()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"msg":"kept"`) || !strings.Contains(string(data), `"tool":"test"`) {
+		t.Errorf("log = %s", data)
+	}
+}
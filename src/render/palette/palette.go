@@ -0,0 +1,125 @@
+// Package palette is the command palette shared by the cover letter
+// editor and the file picker: every action in a tool's key map in a
+// filterable list, run by sending the chosen action's first key. Each
+// tool keeps its own key map and decides which actions to offer.
+package palette
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// action is one entry in the palette: a named binding from a key map.
+type action struct {
+	name    string
+	binding key.Binding
+	label   func(string) string
+}
+
+func (a action) FilterValue() string { return a.binding.Help().Desc + " " + a.name }
+func (a action) Title() string       { return a.binding.Help().Desc }
+func (a action) Description() string {
+	keys := a.binding.Keys()
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = a.label(k)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// Options configures a palette.
+type Options struct {
+	Width, Height int
+	TitleStyle    lipgloss.Style
+	// KeyLabel shows a key name for display, e.g. "ctrl+s" as "Ctrl+S".
+	// Nil shows keys as tea names them.
+	KeyLabel func(string) string
+}
+
+// Palette is an open command palette.
+type Palette struct {
+	list list.Model
+}
+
+// New lists the enabled actions, leaving out those named in skip, sorted
+// by description. It is filtering as soon as it opens, so the first key
+// typed narrows the list.
+func New(actions map[string]*key.Binding, skip map[string]bool, o Options) *Palette {
+	label := o.KeyLabel
+	if label == nil {
+		label = func(k string) string { return k }
+	}
+	var entries []action
+	for name, b := range actions {
+		if !skip[name] && b.Enabled() {
+			entries = append(entries, action{name, *b, label})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].binding.Help().Desc < entries[j].binding.Help().Desc
+	})
+	items := make([]list.Item, len(entries))
+	for i, a := range entries {
+		items[i] = a
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), o.Width, o.Height)
+	l.Title = "Commands"
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("command", "commands")
+	l.Styles.Title = o.TitleStyle
+	l, _ = l.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return &Palette{list: l}
+}
+
+// Update handles msg while the palette is open. Esc clears a filter
+// first, then closes; enter closes and returns the chosen action's
+// binding for the caller to run. Everything else, including the filter
+// matches the list works out in the background, goes to the list.
+func (p *Palette) Update(msg tea.Msg) (chosen *key.Binding, closed bool, cmd tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			if p.list.FilterValue() == "" {
+				return nil, true, nil
+			}
+		case "enter":
+			a, ok := p.list.SelectedItem().(action)
+			if !ok {
+				return nil, true, nil
+			}
+			return &a.binding, true, nil
+		}
+	}
+	p.list, cmd = p.list.Update(msg)
+	return nil, false, cmd
+}
+
+// SetSize fits the palette to width by height cells.
+func (p *Palette) SetSize(width, height int) {
+	p.list.SetSize(width, height)
+}
+
+// View draws the palette.
+func (p *Palette) View() string {
+	return p.list.View()
+}
+
+// KeyMsg is the key press that tea reports as k, as in "ctrl+s" or "q".
+func KeyMsg(k string) tea.KeyMsg {
+	var alt bool
+	if rest, ok := strings.CutPrefix(k, "alt+"); ok && rest != "" {
+		alt, k = true, rest
+	}
+	for t := tea.KeyF20; t <= tea.KeyBackspace; t++ {
+		if t != tea.KeyRunes && (tea.Key{Type: t}).String() == k {
+			return tea.KeyMsg{Type: t, Alt: alt}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k), Alt: alt}
+}
@@ -0,0 +1,85 @@
+package palette
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testActions() map[string]*key.Binding {
+	save := key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save"))
+	raw := key.NewBinding(key.WithKeys("ctrl+o", "f2"), key.WithHelp("ctrl+o", "raw view"))
+	mark := key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark"))
+	off := key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "suspend"), key.WithDisabled())
+	return map[string]*key.Binding{"save": &save, "raw": &raw, "mark": &mark, "suspend": &off}
+}
+
+func TestPalette(t *testing.T) {
+	upper := func(k string) string { return strings.ToUpper(k) }
+	p := New(testActions(), map[string]bool{"mark": true}, Options{Width: 80, Height: 20, KeyLabel: upper})
+	view := p.View()
+	for _, want := range []string{"raw view", "CTRL+O, F2", "save", "CTRL+S"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("palette lacks %q:\n%s", want, view)
+		}
+	}
+	for _, skipped := range []string{"mark", "suspend"} {
+		if strings.Contains(view, skipped) {
+			t.Errorf("palette offers %s:\n%s", skipped, view)
+		}
+	}
+
+	var cmd tea.Cmd
+	for _, r := range "raw" {
+		_, _, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	deliverMatches(p, cmd)
+	if items := p.list.VisibleItems(); len(items) != 1 {
+		t.Fatalf("filtering for raw shows %d actions", len(items))
+	}
+
+	// Esc clears the filter before it closes the palette.
+	if _, closed, _ := p.Update(tea.KeyMsg{Type: tea.KeyEsc}); closed {
+		t.Error("esc with a filter typed closed the palette")
+	}
+	if _, closed, _ := p.Update(tea.KeyMsg{Type: tea.KeyEsc}); !closed {
+		t.Error("esc with no filter didn't close the palette")
+	}
+
+	p = New(testActions(), nil, Options{Width: 80, Height: 20})
+	for _, r := range "save" {
+		_, _, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	deliverMatches(p, cmd)
+	b, closed, _ := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !closed || b == nil || b.Help().Desc != "save" {
+		t.Errorf("enter chose %v, closed = %v; want save", b, closed)
+	}
+}
+
+func TestKeyMsg(t *testing.T) {
+	for _, k := range []string{"ctrl+s", "alt+p", "f1", "f5", "esc", "enter", "tab", " ", "q", "?", "ctrl+home", "alt+d", "ctrl+@"} {
+		if got := KeyMsg(k).String(); got != k {
+			t.Errorf("KeyMsg(%q) reads as %q", k, got)
+		}
+	}
+}
+
+// deliverMatches runs cmd and hands p any filter matches it produces,
+// which a list works out in the background.
+func deliverMatches(p *Palette, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			deliverMatches(p, c)
+		}
+	case list.FilterMatchesMsg:
+		p.Update(msg)
+	}
+}
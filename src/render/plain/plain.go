@@ -0,0 +1,37 @@
+// Package plain is the -plain screen reader mode of the cover letter
+// editor and the file picker: no colour, emoji or box drawing, no mouse,
+// and what is going on spelled out in words. Each tool swaps its own
+// styles; this package holds the switch they share.
+package plain
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var enabled bool
+
+// Enable turns plain mode on and drops colour from everything lipgloss
+// renders. The returned func puts both back, for tests.
+func Enable() (restore func()) {
+	was, profile := enabled, lipgloss.ColorProfile()
+	enabled = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+	return func() {
+		enabled = was
+		lipgloss.SetColorProfile(profile)
+	}
+}
+
+// Enabled reports whether -plain is on.
+func Enabled() bool {
+	return enabled
+}
+
+// Icon returns the emoji s, or alt in its place under -plain.
+func Icon(s, alt string) string {
+	if enabled {
+		return alt
+	}
+	return s
+}
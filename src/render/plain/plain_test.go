@@ -0,0 +1,23 @@
+package plain
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestEnable(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	if Enabled() || Icon("📁", "[dir]") != "📁" {
+		t.Fatal("plain mode should start off")
+	}
+	restore := Enable()
+	if !Enabled() || Icon("📁", "[dir]") != "[dir]" || lipgloss.ColorProfile() != termenv.Ascii {
+		t.Error("Enable should switch to words and drop colour")
+	}
+	restore()
+	if Enabled() || lipgloss.ColorProfile() != profile {
+		t.Error("restore should put the mode and the colour profile back")
+	}
+}
@@ -0,0 +1,64 @@
+// Package profiling adds the hidden -pprof flag shared by the cover letter
+// editor and the standalone markdown renderer, for finding where a slow
+// render spends its time.
+package profiling
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Flag names the hidden -pprof flag. It is left out of -help: it is for
+// chasing slow renders, not for everyday use.
+const Flag = "pprof"
+
+// HideFlag keeps -pprof out of the usage message.
+func HideFlag() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		shown := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		shown.SetOutput(out)
+		flag.VisitAll(func(f *flag.Flag) {
+			if f.Name != Flag {
+				shown.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+		shown.PrintDefaults()
+	}
+}
+
+// Start writes a CPU profile to prefix.cpu until the returned func is
+// called, which then writes a heap profile to prefix.mem. Read them with
+// "go tool pprof". An empty prefix profiles nothing.
+func Start(prefix string) (func() error, error) {
+	if prefix == "" {
+		return func() error { return nil }, nil
+	}
+	cpu, err := os.Create(prefix + ".cpu")
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, err
+	}
+
+	return func() error {
+		pprof.StopCPUProfile()
+		if err := cpu.Close(); err != nil {
+			return err
+		}
+		mem, err := os.Create(prefix + ".mem")
+		if err != nil {
+			return err
+		}
+		defer mem.Close()
+		// Collect garbage first so the profile shows what is still live.
+		runtime.GC()
+		return pprof.WriteHeapProfile(mem)
+	}, nil
+}
@@ -0,0 +1,55 @@
+package profiling
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aign/render"
+)
+
+func TestStart(t *testing.T) {
+	prefix := filepath.Join(t.TempDir(), "render")
+	stop, err := Start(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := render.Markdown("# Letter\n\nDear Acme,\n", render.Options{Width: 80}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".cpu", ".mem"} {
+		if info, err := os.Stat(prefix + ext); err != nil || info.Size() == 0 {
+			t.Errorf("%s profile: %v", ext, err)
+		}
+	}
+
+	stop, err = Start("")
+	if err != nil || stop() != nil {
+		t.Errorf("Start(\"\") = %v, want a no-op", err)
+	}
+}
+
+func TestHideFlag(t *testing.T) {
+	saved, savedUsage := flag.CommandLine, flag.Usage
+	t.Cleanup(func() { flag.CommandLine, flag.Usage = saved, savedUsage })
+	flag.CommandLine = flag.NewFlagSet("tool", flag.ContinueOnError)
+	var out bytes.Buffer
+	flag.CommandLine.SetOutput(&out)
+	flag.String("width", "", "wrap `width`")
+	flag.String(Flag, "", "profile to `prefix`")
+
+	HideFlag()
+	flag.Usage()
+	if !strings.Contains(out.String(), "-width") {
+		t.Errorf("usage lacks -width:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "-"+Flag) {
+		t.Errorf("usage shows -%s:\n%s", Flag, out.String())
+	}
+}
@@ -0,0 +1,78 @@
+// Package render builds the glamour renderer shared by the cover letter
+// editor and the standalone markdown renderer, so a letter looks the same
+// in the editor's preview as it does when rendered on its own.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/muesli/termenv"
+)
+
+// Options configures a renderer. The zero value renders in the dark style
+// with true colour, no word wrap and the heading markers removed.
+type Options struct {
+	// Style is a built-in glamour style name (dark, light, notty, ...) or
+	// the path to a JSON style file. Empty means dark.
+	Style string
+	// Width is the column to word-wrap at; 0 doesn't wrap.
+	Width int
+	// HeadingPrefixes keeps the style's "#"/"##" markers around H1 and H2
+	// headings instead of showing only the heading text.
+	HeadingPrefixes bool
+	// ColorProfile limits the colours used; the zero value is true colour.
+	ColorProfile termenv.Profile
+}
+
+// New returns a renderer configured by o.
+func New(o Options) (*glamour.TermRenderer, error) {
+	style, err := loadStyle(o.Style)
+	if err != nil {
+		return nil, err
+	}
+	if !o.HeadingPrefixes {
+		style.H1.Prefix, style.H1.Suffix = "", ""
+		style.H2.Prefix, style.H2.Suffix = "", ""
+	}
+	return glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithWordWrap(o.Width),
+		glamour.WithColorProfile(o.ColorProfile),
+	)
+}
+
+// Markdown renders text with a renderer configured by o.
+func Markdown(text string, o Options) (string, error) {
+	r, err := New(o)
+	if err != nil {
+		return "", err
+	}
+	return r.Render(text)
+}
+
+// loadStyle looks name up among glamour's built-in styles, falling back
+// to reading it as a JSON style file. The result is a copy, so it can be
+// changed without affecting the built-in.
+func loadStyle(name string) (ansi.StyleConfig, error) {
+	if name == "" {
+		name = styles.DarkStyle
+	}
+	if s, ok := styles.DefaultStyles[name]; ok {
+		return *s, nil
+	}
+
+	var style ansi.StyleConfig
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return style, fmt.Errorf("style %q: %w", name, err)
+	}
+	if err := json.Unmarshal(data, &style); err != nil {
+		return style, fmt.Errorf("style %s: %w", name, err)
+	}
+	return style, nil
+}
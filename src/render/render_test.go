@@ -0,0 +1,38 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// sgr matches the colour and weight escapes glamour wraps text in.
+var sgr = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestHeadingPrefixes(t *testing.T) {
+	text := "# Title\n\n## Section\n\nBody.\n"
+
+	out, err := Markdown(text, Options{Width: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = sgr.ReplaceAllString(out, "")
+	if strings.Contains(out, "## Section") || !strings.Contains(out, "Section") {
+		t.Errorf("heading markers should be removed:\n%s", out)
+	}
+
+	out, err = Markdown(text, Options{Width: 40, HeadingPrefixes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = sgr.ReplaceAllString(out, "")
+	if !strings.Contains(out, "## Section") {
+		t.Errorf("heading markers should be kept:\n%s", out)
+	}
+}
+
+func TestUnknownStyle(t *testing.T) {
+	if _, err := New(Options{Style: "no-such-style"}); err == nil {
+		t.Error("expected an error for a style that is neither built in nor a file")
+	}
+}
@@ -0,0 +1,20 @@
+// Package terminal puts the terminal back in order after a crash in the
+// cover letter editor, the file picker or the markdown pager.
+package terminal
+
+import "io"
+
+// Reset switches off what a tool may have switched on: mouse reporting,
+// the hidden cursor and the alternate screen.
+const Reset = "\x1b[?1000l\x1b[?1002l\x1b[?1003l\x1b[?1006l\x1b[?25h\x1b[?1049l"
+
+// RestoreOnPanic is deferred around each program run. Bubbletea already
+// restores the terminal for panics in Update, View and commands; this
+// catches anything else, resets tty and lets the panic carry on so its
+// trace lands on a usable screen.
+func RestoreOnPanic(tty io.Writer) {
+	if r := recover(); r != nil {
+		io.WriteString(tty, Reset)
+		panic(r)
+	}
+}
@@ -0,0 +1,22 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRestoreOnPanic(t *testing.T) {
+	var tty bytes.Buffer
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want the original panic", r)
+		}
+		if tty.String() != Reset {
+			t.Errorf("tty = %q, want the reset sequence", tty.String())
+		}
+	}()
+	func() {
+		defer RestoreOnPanic(&tty)
+		panic("boom")
+	}()
+}